@@ -1,52 +1,129 @@
 package main
 
 import (
+	"StantStantov/ASS/internal/config"
+	"StantStantov/ASS/internal/prometheus"
+	"StantStantov/ASS/internal/report"
 	"StantStantov/ASS/internal/simulation"
-	"StantStantov/ASS/internal/simulation/framebuffer"
+	"StantStantov/ASS/internal/simulation/clock"
+	"StantStantov/ASS/internal/simulation/loghistory"
+	"StantStantov/ASS/internal/sweep"
 	"StantStantov/ASS/internal/ui"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"os"
 	"runtime/debug"
+	"time"
 
 	"github.com/StantStantov/rps/swamp/logging"
 	"github.com/StantStantov/rps/swamp/logging/logfmt"
 )
 
 func main() {
-	logFile, err :=os.Create(".logs")
+	cfg := config.NewConfig()
+	if err := config.Parse(cfg, os.Args[0], os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if cfg.Seed == 0 {
+		cfg.Seed = uint64(time.Now().UnixNano())
+	}
+
+	output := io.Writer(os.Stdout)
+	if cfg.OutputPath != "" {
+		outputFile, err := os.Create(cfg.OutputPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer outputFile.Close()
+
+		output = outputFile
+	}
+
+	if len(cfg.Sweep) != 0 {
+		if err := runSweep(cfg, output); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		return
+	}
+	if cfg.Replications != 0 {
+		if err := runReplications(cfg, output); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		return
+	}
+
+	saved := simulation.Snapshot{}
+	if cfg.ResumePath != "" {
+		snapshot, err := simulation.LoadSnapshot(cfg.ResumePath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		saved = snapshot
+		cfg = config.WithRunControls(saved.Config, cfg)
+	}
+
+	logFile, err := os.Create(cfg.LogPath)
 	if err != nil {
 		panic(err)
 	}
 
-	logBuffer := &framebuffer.Buffer{}
-	framebuffer.InitBuffer(logBuffer)
+	logHistory := loghistory.NewHistory(cfg.LogHistorySize, config.LogHistoryUnit(cfg))
 
 	logger := logging.NewLogger(
-		io.MultiWriter(logBuffer, logFile),
+		io.MultiWriter(logHistory, logFile),
 		logfmt.MainFormat,
-		logging.LevelDebug,
+		config.LogLevel(cfg),
 		256,
 	)
 
-	msPerUpdate := float64(0.100)
-	agentsAmount := uint64(10)
-	respondersAmount := uint64(20)
-	minChanceToCrash := float32(0.1)
-	alertsCapacity := uint64(32)
-	minChanceToHandle := float32(0.95)
-
 	simulation.Init(
-		msPerUpdate,
-		agentsAmount,
-		respondersAmount,
-		minChanceToCrash,
-		alertsCapacity,
-		minChanceToHandle,
-		logBuffer,
+		cfg,
+		logHistory,
 		logger,
 	)
-	ui.Init(simulation.CommandsSystem, logBuffer)
+	if cfg.ResumePath != "" {
+		simulation.RestoreSnapshot(saved)
+	}
+
+	if cfg.MetricsAddress != "" {
+		server, err := prometheus.Start(cfg.MetricsAddress, simulation.MetricsSystem)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer server.Close()
+	}
+
+	if cfg.Headless {
+		simulation.RunTicks(cfg.TicksAmount, func() bool {
+			return cfg.StopTime > 0 && clock.Now(simulation.Clock) >= cfg.StopTime
+		})
+		if cfg.SnapshotPath != "" {
+			if err := simulation.SaveSnapshot(cfg.SnapshotPath); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+		}
+		writeSummary(cfg, output)
+
+		return
+	}
+
+	ui.Init(simulation.CommandsSystem, logHistory)
 
 	go func() {
 		defer func() {
@@ -62,5 +139,63 @@ func main() {
 	}()
 	ui.RunEventLoop()
 
-	ui.DrawFinalTable()
+	writeSummary(cfg, output)
+}
+
+func writeSummary(cfg *config.Config, output io.Writer) {
+	if cfg.SummaryFormat == "table" {
+		ui.DrawFinalTable(output)
+
+		return
+	}
+
+	if err := report.WriteExport(output, cfg.SummaryFormat, report.CollectExport()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func runSweep(cfg *config.Config, output io.Writer) error {
+	ranges := make([]sweep.Range, len(cfg.Sweep))
+	for i, text := range cfg.Sweep {
+		valuesRange, err := sweep.ParseRange(text)
+		if err != nil {
+			return err
+		}
+		ranges[i] = valuesRange
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	runs, runsErr := sweep.RunSweep(executable, cfg, ranges, cfg.Workers)
+	if err := sweep.WriteCSV(output, ranges, runs); err != nil {
+		return err
+	}
+
+	return runsErr
+}
+
+func runReplications(cfg *config.Config, output io.Writer) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	estimates, runsErr := sweep.RunReplications(executable, cfg)
+	if estimates.Replications < 2 {
+		return runsErr
+	}
+	if cfg.SummaryFormat == "table" {
+		ui.DrawEstimatesTable(output, estimates)
+
+		return runsErr
+	}
+	if err := report.WriteEstimates(output, cfg.SummaryFormat, estimates); err != nil {
+		return err
+	}
+
+	return runsErr
 }
@@ -0,0 +1,18 @@
+package collections
+
+import (
+	"fmt"
+
+	"github.com/StantStantov/rps/swamp/bools"
+	"github.com/StantStantov/rps/swamp/collections/sparsemap"
+)
+
+func ClearSparseMap[V any](sparseMap *sparsemap.SparseMap[uint64, V]) {
+	keys := make([]uint64, sparsemap.Length(sparseMap))
+	keys = sparsemap.GetAllKeysFromSparseMap(sparseMap, keys)
+	removed := make([]bool, len(keys))
+	removed = sparsemap.RemoveFromSparseMap(sparseMap, removed, keys...)
+	if bools.AnyFalse(removed...) {
+		panic(fmt.Sprintf("Clear Sparse Map %v %v", keys, removed))
+	}
+}
@@ -0,0 +1,104 @@
+package queueing
+
+import "math"
+
+type Prediction struct {
+	IsStable        bool
+	Utilisation     float64
+	WaitingTime     float64
+	TimeInSystem    float64
+	QueueLength     float64
+	LossProbability float64
+}
+
+func ErlangB(servers uint64, load float64) float64 {
+	blocking := 1.0
+	for n := uint64(1); n <= servers; n++ {
+		blocking = load * blocking / (float64(n) + load*blocking)
+	}
+
+	return blocking
+}
+
+func ErlangC(servers uint64, load float64) float64 {
+	utilisation := load / float64(servers)
+	if utilisation >= 1 {
+		return 1
+	}
+
+	blocking := ErlangB(servers, load)
+
+	return blocking / (1 - utilisation*(1-blocking))
+}
+
+func MMc(arrivalRate float64, serviceRate float64, servers uint64) Prediction {
+	load := arrivalRate / serviceRate
+	utilisation := load / float64(servers)
+	if !(serviceRate > 0) || servers == 0 || !(utilisation < 1) {
+		return Prediction{IsStable: false}
+	}
+
+	queueLength := ErlangC(servers, load) * utilisation / (1 - utilisation)
+	waitingTime := 0.0
+	if arrivalRate > 0 {
+		waitingTime = queueLength / arrivalRate
+	}
+
+	return Prediction{
+		IsStable:        true,
+		Utilisation:     utilisation,
+		WaitingTime:     waitingTime,
+		TimeInSystem:    waitingTime + 1/serviceRate,
+		QueueLength:     queueLength,
+		LossProbability: 0,
+	}
+}
+
+// With capacity equal to servers the loss probability is Erlang-B.
+func MMcK(arrivalRate float64, serviceRate float64, servers uint64, capacity uint64) Prediction {
+	if !(serviceRate > 0) || servers == 0 || capacity == 0 {
+		return Prediction{IsStable: false}
+	}
+	busyServers := min(servers, capacity)
+
+	load := arrivalRate / serviceRate
+	logProbabilities := make([]float64, capacity+1)
+	for n := uint64(1); n <= capacity; n++ {
+		logProbabilities[n] = logProbabilities[n-1] + math.Log(load) - math.Log(float64(min(n, busyServers)))
+	}
+
+	maxLogProbability := math.Inf(-1)
+	for _, logProbability := range logProbabilities {
+		maxLogProbability = max(maxLogProbability, logProbability)
+	}
+	probabilities := make([]float64, capacity+1)
+	sum := 0.0
+	for n, logProbability := range logProbabilities {
+		probabilities[n] = math.Exp(logProbability - maxLogProbability)
+		sum += probabilities[n]
+	}
+
+	queueLength := 0.0
+	for n := range probabilities {
+		probabilities[n] /= sum
+		if uint64(n) > busyServers {
+			queueLength += float64(uint64(n)-busyServers) * probabilities[n]
+		}
+	}
+
+	lossProbability := probabilities[capacity]
+	effectiveRate := arrivalRate * (1 - lossProbability)
+	waitingTime := 0.0
+	if effectiveRate > 0 {
+		waitingTime = queueLength / effectiveRate
+	}
+
+	return Prediction{
+		IsStable:        true,
+		Utilisation:     effectiveRate / (serviceRate * float64(servers)),
+		WaitingTime:     waitingTime,
+		TimeInSystem:    waitingTime + 1/serviceRate,
+		QueueLength:     queueLength,
+		LossProbability: lossProbability,
+	}
+}
@@ -0,0 +1,145 @@
+package stats
+
+import "math"
+
+const (
+	betaIterations = 200
+	betaEpsilon    = 3e-14
+	quantileSteps  = 200
+)
+
+func Mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+	}
+
+	return sum / float64(len(values))
+}
+
+func StandardDeviation(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+
+	mean := Mean(values)
+	sum := 0.0
+	for _, value := range values {
+		sum += (value - mean) * (value - mean)
+	}
+
+	return math.Sqrt(sum / float64(len(values)-1))
+}
+
+func StudentHalfWidth(values []float64, confidence float64) float64 {
+	if len(values) < 2 {
+		return math.Inf(1)
+	}
+
+	amount := float64(len(values))
+	quantile := StudentQuantile(1-(1-confidence)/2, amount-1)
+
+	return quantile * StandardDeviation(values) / math.Sqrt(amount)
+}
+
+func StudentQuantile(probability float64, freedom float64) float64 {
+	if probability == 0.5 {
+		return 0
+	}
+	if probability < 0.5 {
+		return -StudentQuantile(1-probability, freedom)
+	}
+
+	low, high := 0.0, 1.0
+	for StudentCDF(high, freedom) < probability {
+		low, high = high, high*2
+	}
+	for range quantileSteps {
+		middle := (low + high) / 2
+		if StudentCDF(middle, freedom) < probability {
+			low = middle
+		} else {
+			high = middle
+		}
+	}
+
+	return (low + high) / 2
+}
+
+func StudentCDF(t float64, freedom float64) float64 {
+	tail := 0.5 * regularizedBeta(freedom/(freedom+t*t), freedom/2, 0.5)
+	if t < 0 {
+		return tail
+	}
+
+	return 1 - tail
+}
+
+func regularizedBeta(x float64, a float64, b float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+
+	lgammaA, _ := math.Lgamma(a)
+	lgammaB, _ := math.Lgamma(b)
+	lgammaAB, _ := math.Lgamma(a + b)
+	front := math.Exp(lgammaAB - lgammaA - lgammaB + a*math.Log(x) + b*math.Log(1-x))
+	if x < (a+1)/(a+b+2) {
+		return front * betaFraction(x, a, b) / a
+	}
+
+	return 1 - front*betaFraction(1-x, b, a)/b
+}
+
+// Lentz's method for the continued fraction of the incomplete beta function.
+func betaFraction(x float64, a float64, b float64) float64 {
+	tiny := 1e-300
+
+	c := 1.0
+	d := 1 - (a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	fraction := d
+	for i := 1; i <= betaIterations; i++ {
+		m := float64(i)
+
+		numerator := m * (b - m) * x / ((a + 2*m - 1) * (a + 2*m))
+		d = 1 + numerator*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + numerator/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		fraction *= d * c
+
+		numerator = -(a + m) * (a + b + m) * x / ((a + 2*m) * (a + 2*m + 1))
+		d = 1 + numerator*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + numerator/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		fraction *= delta
+		if math.Abs(delta-1) < betaEpsilon {
+			break
+		}
+	}
+
+	return fraction
+}
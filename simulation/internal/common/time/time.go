@@ -5,5 +5,9 @@ import "time"
 func TimeNowInSeconds() float64 {
 	timestamp := time.Now()
 
-	return float64(timestamp.UnixNano() / 1e9)
+	return float64(timestamp.UnixNano()) / 1e9
+}
+
+func SleepInSeconds(seconds float64) {
+	time.Sleep(time.Duration(seconds * 1e9))
 }
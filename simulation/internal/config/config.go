@@ -0,0 +1,561 @@
+package config
+
+import (
+	"StantStantov/ASS/internal/simulation/agents"
+	"StantStantov/ASS/internal/simulation/buffer"
+	"StantStantov/ASS/internal/simulation/events"
+	"StantStantov/ASS/internal/simulation/loghistory"
+	"StantStantov/ASS/internal/simulation/pools"
+	"StantStantov/ASS/internal/simulation/responders"
+	"StantStantov/ASS/internal/simulation/warmup"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/StantStantov/rps/swamp/logging"
+)
+
+type Config struct {
+	Seed        uint64  `json:"seed"`
+	MsPerUpdate float64 `json:"ms_per_update"`
+	Engine      string  `json:"engine"`
+
+	AgentsAmount     uint64    `json:"agents_amount"`
+	MinChanceToCrash float32   `json:"min_chance_to_crash"`
+	ArrivalModel     string    `json:"arrival_model"`
+	ArrivalRate      float64   `json:"arrival_rate"`
+	AgentsRates      []float64 `json:"agents_rates"`
+
+	AlertsCapacity   uint64  `json:"alerts_capacity"`
+	RefusalPolicy    string  `json:"refusal_policy"`
+	OverflowCapacity uint64  `json:"overflow_capacity"`
+	QueueDiscipline  string  `json:"queue_discipline"`
+	JobTimeout       float64 `json:"job_timeout"`
+
+	RespondersAmount     uint64    `json:"responders_amount"`
+	RespondersPriorities []uint64  `json:"responders_priorities"`
+	MinChanceToHandle    float32   `json:"min_chance_to_handle"`
+	ServiceLaw           string    `json:"service_law"`
+	ServiceRate          float64   `json:"service_rate"`
+	ServiceMin           float64   `json:"service_min"`
+	ServiceMax           float64   `json:"service_max"`
+	ServicePhases        uint64    `json:"service_phases"`
+	ServiceDuration      float64   `json:"service_duration"`
+	ServiceSamples       []float64 `json:"service_samples"`
+
+	Headless    bool    `json:"headless"`
+	TicksAmount uint64  `json:"ticks_amount"`
+	StopTime    float64 `json:"stop_time"`
+	OutputPath  string  `json:"output_path"`
+
+	WarmupTicks    uint64  `json:"warmup_ticks"`
+	WarmupTime     float64 `json:"warmup_time"`
+	WarmupDetector string  `json:"warmup_detector"`
+
+	SummaryFormat string   `json:"summary_format"`
+	Sweep         []string `json:"sweep"`
+	Workers       uint64   `json:"workers"`
+
+	Replications    uint64  `json:"replications"`
+	MaxReplications uint64  `json:"max_replications"`
+	Confidence      float64 `json:"confidence"`
+	TargetHalfWidth float64 `json:"target_half_width"`
+
+	MetricsAddress     string `json:"metrics_address"`
+	TimeSeriesCapacity uint64 `json:"time_series_capacity"`
+
+	SnapshotPath string `json:"snapshot_path"`
+	ResumePath   string `json:"resume_path"`
+
+	LogPath        string `json:"log_path"`
+	LogLevel       string `json:"log_level"`
+	LogHistorySize uint64 `json:"log_history_size"`
+	LogHistoryUnit string `json:"log_history_unit"`
+}
+
+var SummaryFormatsNames = []string{
+	"table",
+	"json",
+	"csv",
+	"markdown",
+}
+
+var LogLevelsNames = map[string]logging.Level{
+	"debug": logging.LevelDebug,
+	"info":  logging.LevelInfo,
+	"warn":  logging.LevelWarn,
+	"error": logging.LevelError,
+}
+
+func NewConfig() *Config {
+	config := &Config{}
+
+	config.Seed = 0
+	config.MsPerUpdate = 0.100
+	config.Engine = "tick"
+
+	config.AgentsAmount = 10
+	config.MinChanceToCrash = 0.1
+	config.ArrivalModel = "bernoulli"
+	config.ArrivalRate = 1.0
+	config.AgentsRates = nil
+
+	config.AlertsCapacity = 32
+	config.RefusalPolicy = "drop_newest"
+	config.OverflowCapacity = 64
+	config.QueueDiscipline = "fifo"
+	config.JobTimeout = 0
+
+	config.RespondersAmount = 20
+	config.RespondersPriorities = nil
+	config.MinChanceToHandle = 0.95
+	config.ServiceLaw = "chance"
+	config.ServiceRate = 1.0
+	config.ServiceMin = 0.5
+	config.ServiceMax = 1.5
+	config.ServicePhases = 2
+	config.ServiceDuration = 1.0
+	config.ServiceSamples = nil
+
+	config.Headless = false
+	config.TicksAmount = 1000
+	config.StopTime = 0
+	config.OutputPath = ""
+
+	config.WarmupTicks = 0
+	config.WarmupTime = 0
+	config.WarmupDetector = "none"
+
+	config.SummaryFormat = "table"
+	config.Sweep = nil
+	config.Workers = uint64(runtime.NumCPU())
+
+	config.Replications = 0
+	config.MaxReplications = 100
+	config.Confidence = 0.95
+	config.TargetHalfWidth = 0
+
+	config.MetricsAddress = ""
+	config.TimeSeriesCapacity = 1000
+
+	config.SnapshotPath = ""
+	config.ResumePath = ""
+
+	config.LogPath = ".logs"
+	config.LogLevel = "debug"
+	config.LogHistorySize = 10000
+	config.LogHistoryUnit = "lines"
+
+	return config
+}
+
+func Parse(config *Config, name string, args []string) error {
+	scratch := *config
+	scratchSet := flag.NewFlagSet(name, flag.ContinueOnError)
+	configPath := scratchSet.String("config", "", "JSON file with simulation parameters, flags override its values")
+	RegisterFlags(&scratch, scratchSet)
+
+	if err := scratchSet.Parse(args); err != nil {
+		return err
+	}
+	if *configPath == "" {
+		*config = scratch
+
+		return Validate(config)
+	}
+
+	if err := LoadFromFile(config, *configPath); err != nil {
+		return err
+	}
+	if len(scratch.Sweep) != 0 {
+		config.Sweep = nil
+	}
+
+	flagSet := flag.NewFlagSet(name, flag.ContinueOnError)
+	flagSet.String("config", "", "JSON file with simulation parameters, flags override its values")
+	RegisterFlags(config, flagSet)
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+
+	return Validate(config)
+}
+
+func RegisterFlags(config *Config, flagSet *flag.FlagSet) {
+	flagSet.Uint64Var(&config.Seed, "seed", config.Seed, "seed for random streams, 0 picks one from the current time")
+	flagSet.Float64Var(&config.MsPerUpdate, "ms-per-update", config.MsPerUpdate, "simulated seconds per tick")
+	flagSet.StringVar(&config.Engine, "engine", config.Engine, "simulation engine: "+strings.Join(events.EnginesNames, ", "))
+
+	flagSet.Uint64Var(&config.AgentsAmount, "agents", config.AgentsAmount, "amount of agents")
+	flagSet.Var((*float32Value)(&config.MinChanceToCrash), "chance-to-crash", "minimal chance for an agent to stay silent per tick, in [0,1]")
+	flagSet.StringVar(&config.ArrivalModel, "arrival-model", config.ArrivalModel, "arrival model of agents: "+strings.Join(agents.ArrivalModelsNames, ", "))
+	flagSet.Float64Var(&config.ArrivalRate, "arrival-rate", config.ArrivalRate, "alerts per simulated second of each agent for poisson and deterministic arrivals")
+
+	flagSet.Uint64Var(&config.AlertsCapacity, "alerts-capacity", config.AlertsCapacity, "capacity of each agent's alerts buffer")
+	flagSet.StringVar(&config.RefusalPolicy, "refusal-policy", config.RefusalPolicy, "behaviour when an agent's alerts buffer is full: "+strings.Join(buffer.RefusalPoliciesNames, ", "))
+	flagSet.Uint64Var(&config.OverflowCapacity, "overflow-capacity", config.OverflowCapacity, "capacity of the shared overflow area for the spill refusal policy")
+	flagSet.StringVar(&config.QueueDiscipline, "queue-discipline", config.QueueDiscipline, "order in which pending jobs leave the pool: "+strings.Join(pools.DisciplinesNames, ", "))
+	flagSet.Float64Var(&config.JobTimeout, "job-timeout", config.JobTimeout, "simulated seconds a job may wait in the pool for a responder before it expires, 0 to disable")
+
+	flagSet.Uint64Var(&config.RespondersAmount, "responders", config.RespondersAmount, "amount of responders")
+	flagSet.Var((*float32Value)(&config.MinChanceToHandle), "chance-to-handle", "minimal chance for a responder to stay busy per tick, in [0,1]")
+	flagSet.StringVar(&config.ServiceLaw, "service-law", config.ServiceLaw, "service time law of responders: "+strings.Join(responders.ServiceLawsNames, ", "))
+	flagSet.Float64Var(&config.ServiceRate, "service-rate", config.ServiceRate, "rate per simulated second for exponential service and each erlang phase")
+	flagSet.Float64Var(&config.ServiceMin, "service-min", config.ServiceMin, "lower bound in simulated seconds for uniform service")
+	flagSet.Float64Var(&config.ServiceMax, "service-max", config.ServiceMax, "upper bound in simulated seconds for uniform service")
+	flagSet.Uint64Var(&config.ServicePhases, "service-phases", config.ServicePhases, "amount of phases for erlang service")
+	flagSet.Float64Var(&config.ServiceDuration, "service-duration", config.ServiceDuration, "simulated seconds for deterministic service or per alert for per_alert service")
+
+	flagSet.BoolVar(&config.Headless, "headless", config.Headless, "run without the TUI and print the final table")
+	flagSet.Uint64Var(&config.TicksAmount, "ticks", config.TicksAmount, "amount of ticks to run in headless mode")
+	flagSet.Float64Var(&config.StopTime, "stop-time", config.StopTime, "stop headless mode once simulated seconds reach this value, 0 to disable")
+	flagSet.StringVar(&config.OutputPath, "output", config.OutputPath, "file to write the final table into, stdout if empty")
+
+	flagSet.Uint64Var(&config.WarmupTicks, "warmup-ticks", config.WarmupTicks, "reset statistics after this amount of ticks, 0 to disable")
+	flagSet.Float64Var(&config.WarmupTime, "warmup-time", config.WarmupTime, "reset statistics once simulated seconds reach this value, 0 to disable")
+	flagSet.StringVar(&config.WarmupDetector, "warmup-detector", config.WarmupDetector, "detector of the steady state to reset statistics at: "+strings.Join(warmup.DetectorsNames, ", "))
+
+	flagSet.StringVar(&config.SummaryFormat, "summary-format", config.SummaryFormat, "format of the final table: "+strings.Join(SummaryFormatsNames, ", "))
+	flagSet.Var((*stringsValue)(&config.Sweep), "sweep", "parameter range to sweep as flag=a,b,c or flag=start:stop:step, repeat for a grid")
+	flagSet.Uint64Var(&config.Workers, "workers", config.Workers, "amount of parallel runs while sweeping or replicating")
+
+	flagSet.Uint64Var(&config.Replications, "replications", config.Replications, "amount of runs with consecutive seeds to estimate confidence intervals, 0 to disable")
+	flagSet.Uint64Var(&config.MaxReplications, "max-replications", config.MaxReplications, "upper bound of runs while waiting for the target half-width")
+	flagSet.Float64Var(&config.Confidence, "confidence", config.Confidence, "confidence level of the intervals, in (0,1)")
+	flagSet.Float64Var(&config.TargetHalfWidth, "target-half-width", config.TargetHalfWidth, "keep adding runs until every interval half-width is below this fraction of its mean or below the absolute tolerance of its statistic, 0 to disable")
+
+	flagSet.StringVar(&config.MetricsAddress, "metrics-address", config.MetricsAddress, "address like localhost:9090 to serve Prometheus metrics at /metrics, empty to disable")
+
+	flagSet.Uint64Var(&config.TimeSeriesCapacity, "time-series-capacity", config.TimeSeriesCapacity, "amount of last ticks kept for the charts, 0 to disable recording")
+
+	flagSet.StringVar(&config.SnapshotPath, "snapshot", config.SnapshotPath, "file to save the simulation state into when the run ends or on the s key, empty to disable")
+	flagSet.StringVar(&config.ResumePath, "resume", config.ResumePath, "snapshot file to continue a saved run from, its parameters replace the model ones")
+
+	flagSet.StringVar(&config.LogPath, "log-path", config.LogPath, "file to write logs into")
+	flagSet.StringVar(&config.LogLevel, "log-level", config.LogLevel, "minimal level of logs: debug, info, warn or error")
+	flagSet.Uint64Var(&config.LogHistorySize, "log-history", config.LogHistorySize, "amount of log lines or ticks the logs window can scroll back through")
+	flagSet.StringVar(&config.LogHistoryUnit, "log-history-unit", config.LogHistoryUnit, "unit of the log history size: lines or ticks")
+}
+
+func LoadFromFile(config *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config %q: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, config); err != nil {
+		return fmt.Errorf("parse config %q: %w", path, err)
+	}
+
+	return nil
+}
+
+func Validate(config *Config) error {
+	errs := []error{}
+
+	if !(config.MsPerUpdate > 0) {
+		errs = append(errs, fmt.Errorf("ms_per_update must be positive, got %v", config.MsPerUpdate))
+	}
+	if _, ok := events.EngineFromName(config.Engine); !ok {
+		errs = append(errs, fmt.Errorf("engine must be one of %s, got %q", strings.Join(events.EnginesNames, ", "), config.Engine))
+	}
+	if config.AgentsAmount == 0 {
+		errs = append(errs, errors.New("agents_amount must be at least 1"))
+	}
+	if !isProbability(config.MinChanceToCrash) {
+		errs = append(errs, fmt.Errorf("min_chance_to_crash must be in [0,1], got %v", config.MinChanceToCrash))
+	}
+	if _, ok := agents.ArrivalModelFromName(config.ArrivalModel); !ok {
+		errs = append(errs, fmt.Errorf("arrival_model must be one of %s, got %q", strings.Join(agents.ArrivalModelsNames, ", "), config.ArrivalModel))
+	}
+	if config.ArrivalRate < 0 || math.IsNaN(config.ArrivalRate) {
+		errs = append(errs, fmt.Errorf("arrival_rate must not be negative, got %v", config.ArrivalRate))
+	}
+	if len(config.AgentsRates) != 0 && uint64(len(config.AgentsRates)) != config.AgentsAmount {
+		errs = append(errs, fmt.Errorf("agents_rates must hold one rate per agent, got %d rates for %d agents", len(config.AgentsRates), config.AgentsAmount))
+	}
+	for i, rate := range config.AgentsRates {
+		if rate < 0 || math.IsNaN(rate) {
+			errs = append(errs, fmt.Errorf("agents_rates[%d] must not be negative, got %v", i, rate))
+		}
+	}
+	if config.AlertsCapacity == 0 {
+		errs = append(errs, errors.New("alerts_capacity must be at least 1"))
+	}
+	if _, ok := buffer.RefusalPolicyFromName(config.RefusalPolicy); !ok {
+		errs = append(errs, fmt.Errorf("refusal_policy must be one of %s, got %q", strings.Join(buffer.RefusalPoliciesNames, ", "), config.RefusalPolicy))
+	}
+	if _, ok := pools.DisciplineFromName(config.QueueDiscipline); !ok {
+		errs = append(errs, fmt.Errorf("queue_discipline must be one of %s, got %q", strings.Join(pools.DisciplinesNames, ", "), config.QueueDiscipline))
+	}
+	if config.JobTimeout < 0 || math.IsNaN(config.JobTimeout) {
+		errs = append(errs, fmt.Errorf("job_timeout must not be negative, got %v", config.JobTimeout))
+	}
+	if config.RespondersAmount == 0 {
+		errs = append(errs, errors.New("responders_amount must be at least 1"))
+	}
+	if len(config.RespondersPriorities) != 0 && uint64(len(config.RespondersPriorities)) != config.RespondersAmount {
+		errs = append(errs, fmt.Errorf("responders_priorities must hold one priority per responder, got %d priorities for %d responders", len(config.RespondersPriorities), config.RespondersAmount))
+	}
+	if !isProbability(config.MinChanceToHandle) {
+		errs = append(errs, fmt.Errorf("min_chance_to_handle must be in [0,1], got %v", config.MinChanceToHandle))
+	}
+	errs = append(errs, validateService(config)...)
+	if config.StopTime < 0 || math.IsNaN(config.StopTime) {
+		errs = append(errs, fmt.Errorf("stop_time must not be negative, got %v", config.StopTime))
+	}
+	errs = append(errs, validateWarmup(config)...)
+	if !slices.Contains(SummaryFormatsNames, config.SummaryFormat) {
+		errs = append(errs, fmt.Errorf("summary_format must be one of %s, got %q", strings.Join(SummaryFormatsNames, ", "), config.SummaryFormat))
+	}
+	if config.Workers == 0 {
+		errs = append(errs, errors.New("workers must be at least 1"))
+	}
+	errs = append(errs, validateReplications(config)...)
+	if config.ResumePath != "" && (len(config.Sweep) != 0 || config.Replications != 0) {
+		errs = append(errs, errors.New("resume_path cannot be combined with sweep or replications"))
+	}
+	if config.LogPath == "" {
+		errs = append(errs, errors.New("log_path must not be empty"))
+	}
+	if _, ok := LogLevelsNames[config.LogLevel]; !ok {
+		errs = append(errs, fmt.Errorf("log_level must be one of debug, info, warn or error, got %q", config.LogLevel))
+	}
+	if config.LogHistorySize == 0 {
+		errs = append(errs, errors.New("log_history_size must be at least 1"))
+	}
+	if _, ok := loghistory.UnitFromName(config.LogHistoryUnit); !ok {
+		errs = append(errs, fmt.Errorf("log_history_unit must be one of %s, got %q", strings.Join(loghistory.UnitsNames, ", "), config.LogHistoryUnit))
+	}
+
+	return errors.Join(errs...)
+}
+
+// WithRunControls keeps the model parameters of a saved config and takes
+// the settings of how to run it from the current one.
+func WithRunControls(saved *Config, current *Config) *Config {
+	config := *saved
+
+	config.Headless = current.Headless
+	config.TicksAmount = current.TicksAmount
+	config.StopTime = current.StopTime
+	config.OutputPath = current.OutputPath
+	config.SummaryFormat = current.SummaryFormat
+	config.MetricsAddress = current.MetricsAddress
+	config.SnapshotPath = current.SnapshotPath
+	config.ResumePath = current.ResumePath
+	config.LogPath = current.LogPath
+	config.LogLevel = current.LogLevel
+	config.LogHistorySize = current.LogHistorySize
+	config.LogHistoryUnit = current.LogHistoryUnit
+
+	return &config
+}
+
+func Engine(config *Config) events.Engine {
+	engine, _ := events.EngineFromName(config.Engine)
+
+	return engine
+}
+
+func ArrivalModel(config *Config) agents.ArrivalModel {
+	arrivalModel, _ := agents.ArrivalModelFromName(config.ArrivalModel)
+
+	return arrivalModel
+}
+
+func ArrivalRates(config *Config) []float64 {
+	if len(config.AgentsRates) != 0 {
+		return config.AgentsRates
+	}
+
+	rates := make([]float64, config.AgentsAmount)
+	for i := range rates {
+		rates[i] = config.ArrivalRate
+	}
+
+	return rates
+}
+
+func RefusalPolicy(config *Config) buffer.RefusalPolicy {
+	refusalPolicy, _ := buffer.RefusalPolicyFromName(config.RefusalPolicy)
+
+	return refusalPolicy
+}
+
+func QueueDiscipline(config *Config) pools.Discipline {
+	discipline, _ := pools.DisciplineFromName(config.QueueDiscipline)
+
+	return discipline
+}
+
+func RespondersPriorities(config *Config) []uint64 {
+	if len(config.RespondersPriorities) != 0 {
+		return config.RespondersPriorities
+	}
+
+	priorities := make([]uint64, config.RespondersAmount)
+	for i := range priorities {
+		priorities[i] = config.RespondersAmount - uint64(i)
+	}
+
+	return priorities
+}
+
+func ServiceLaw(config *Config) responders.ServiceLaw {
+	serviceLaw, _ := responders.ServiceLawFromName(config.ServiceLaw)
+
+	return serviceLaw
+}
+
+func ServiceParameters(config *Config) responders.ServiceParameters {
+	return responders.ServiceParameters{
+		Rate:     config.ServiceRate,
+		Min:      config.ServiceMin,
+		Max:      config.ServiceMax,
+		Phases:   config.ServicePhases,
+		Duration: config.ServiceDuration,
+		Samples:  config.ServiceSamples,
+	}
+}
+
+func WarmupDetector(config *Config) warmup.Detector {
+	detector, _ := warmup.DetectorFromName(config.WarmupDetector)
+
+	return detector
+}
+
+func LogLevel(config *Config) logging.Level {
+	return LogLevelsNames[config.LogLevel]
+}
+
+func LogHistoryUnit(config *Config) loghistory.Unit {
+	unit, _ := loghistory.UnitFromName(config.LogHistoryUnit)
+
+	return unit
+}
+
+func validateService(config *Config) []error {
+	serviceLaw, ok := responders.ServiceLawFromName(config.ServiceLaw)
+	if !ok {
+		return []error{fmt.Errorf("service_law must be one of %s, got %q", strings.Join(responders.ServiceLawsNames, ", "), config.ServiceLaw)}
+	}
+
+	errs := []error{}
+	switch serviceLaw {
+	case responders.ExponentialService, responders.ErlangService:
+		if !(config.ServiceRate > 0) {
+			errs = append(errs, fmt.Errorf("service_rate must be positive for %s service, got %v", config.ServiceLaw, config.ServiceRate))
+		}
+		if serviceLaw == responders.ErlangService && config.ServicePhases == 0 {
+			errs = append(errs, errors.New("service_phases must be at least 1 for erlang service"))
+		}
+	case responders.UniformService:
+		if config.ServiceMin < 0 || !(config.ServiceMin <= config.ServiceMax) {
+			errs = append(errs, fmt.Errorf("service_min and service_max must satisfy 0 <= min <= max, got %v and %v", config.ServiceMin, config.ServiceMax))
+		}
+	case responders.DeterministicService, responders.PerAlertService:
+		if config.ServiceDuration < 0 || math.IsNaN(config.ServiceDuration) {
+			errs = append(errs, fmt.Errorf("service_duration must not be negative, got %v", config.ServiceDuration))
+		}
+	case responders.EmpiricalService:
+		if len(config.ServiceSamples) == 0 {
+			errs = append(errs, errors.New("service_samples must hold at least one sample for empirical service"))
+		}
+		for i, sample := range config.ServiceSamples {
+			if sample < 0 || math.IsNaN(sample) {
+				errs = append(errs, fmt.Errorf("service_samples[%d] must not be negative, got %v", i, sample))
+			}
+		}
+	}
+
+	return errs
+}
+
+func validateWarmup(config *Config) []error {
+	errs := []error{}
+
+	detector, ok := warmup.DetectorFromName(config.WarmupDetector)
+	if !ok {
+		errs = append(errs, fmt.Errorf("warmup_detector must be one of %s, got %q", strings.Join(warmup.DetectorsNames, ", "), config.WarmupDetector))
+	}
+	if config.WarmupTime < 0 || math.IsNaN(config.WarmupTime) {
+		errs = append(errs, fmt.Errorf("warmup_time must not be negative, got %v", config.WarmupTime))
+	}
+
+	settings := 0
+	for _, isSet := range []bool{config.WarmupTicks != 0, config.WarmupTime != 0, detector != warmup.NoDetector} {
+		if isSet {
+			settings++
+		}
+	}
+	if settings > 1 {
+		errs = append(errs, errors.New("only one of warmup_ticks, warmup_time and warmup_detector may be set"))
+	}
+
+	return errs
+}
+
+func validateReplications(config *Config) []error {
+	errs := []error{}
+
+	if config.Replications == 0 {
+		return errs
+	}
+	if config.Replications < 2 {
+		errs = append(errs, fmt.Errorf("replications must be 0 or at least 2, got %d", config.Replications))
+	}
+	if len(config.Sweep) != 0 {
+		errs = append(errs, errors.New("replications cannot be combined with sweep"))
+	}
+	if !(config.Confidence > 0 && config.Confidence < 1) {
+		errs = append(errs, fmt.Errorf("confidence must be in (0,1), got %v", config.Confidence))
+	}
+	if config.TargetHalfWidth < 0 || math.IsNaN(config.TargetHalfWidth) {
+		errs = append(errs, fmt.Errorf("target_half_width must not be negative, got %v", config.TargetHalfWidth))
+	}
+	if config.TargetHalfWidth > 0 && config.MaxReplications < config.Replications {
+		errs = append(errs, fmt.Errorf("max_replications must be at least replications, got %d for %d replications", config.MaxReplications, config.Replications))
+	}
+
+	return errs
+}
+
+func isProbability(value float32) bool {
+	return value >= 0 && value <= 1
+}
+
+type float32Value float32
+
+func (value *float32Value) Set(text string) error {
+	parsed, err := strconv.ParseFloat(text, 32)
+	if err != nil {
+		return err
+	}
+	*value = float32Value(parsed)
+
+	return nil
+}
+
+func (value *float32Value) String() string {
+	return strconv.FormatFloat(float64(*value), 'g', -1, 32)
+}
+
+type stringsValue []string
+
+func (value *stringsValue) Set(text string) error {
+	*value = append(*value, text)
+
+	return nil
+}
+
+func (value *stringsValue) String() string {
+	return strings.Join(*value, " ")
+}
@@ -0,0 +1,85 @@
+package prometheus
+
+import (
+	"StantStantov/ASS/internal/simulation/metrics"
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	Namespace   = "simulation"
+	ContentType = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+var MetricKindsTypes = []string{
+	"counter",
+	"gauge",
+	"histogram",
+}
+
+func Start(address string, system *metrics.MetricsSystem) (*http.Server, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("listen for metrics on %q: %w", address, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler(system))
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+
+	return server, nil
+}
+
+func Handler(system *metrics.MetricsSystem) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		metricsToWrite := make([]metrics.Metric, metrics.MetricsAmount(system))
+		metricsToWrite = metrics.GetMetrics(system, metricsToWrite)
+
+		writer.Header().Set("Content-Type", ContentType)
+		WriteMetrics(writer, metricsToWrite)
+	})
+}
+
+func WriteMetrics(output io.Writer, metricsToWrite []metrics.Metric) error {
+	writer := bufio.NewWriter(output)
+	for _, metric := range metricsToWrite {
+		name := Namespace + "_" + metric.Name
+		fmt.Fprintf(writer, "# HELP %s %s\n", name, escapeHelp(metric.Description))
+		fmt.Fprintf(writer, "# TYPE %s %s\n", name, MetricKindsTypes[metric.Kind])
+
+		if metric.Kind != metrics.HistogramMetric {
+			fmt.Fprintf(writer, "%s %d\n", name, metric.Value)
+
+			continue
+		}
+
+		for _, bucket := range metric.Buckets {
+			fmt.Fprintf(writer, "%s_bucket{le=\"%s\"} %d\n", name, formatFloat(bucket.UpperBound), bucket.Count)
+		}
+		fmt.Fprintf(writer, "%s_sum %s\n", name, formatFloat(metric.Sum))
+		fmt.Fprintf(writer, "%s_count %d\n", name, metric.Value)
+	}
+
+	return writer.Flush()
+}
+
+func formatFloat(value float64) string {
+	if math.IsInf(value, 1) {
+		return "+Inf"
+	}
+
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func escapeHelp(text string) string {
+	text = strings.ReplaceAll(text, `\`, `\\`)
+
+	return strings.ReplaceAll(text, "\n", `\n`)
+}
@@ -0,0 +1,127 @@
+package report
+
+import (
+	"StantStantov/ASS/internal/common/queueing"
+	"StantStantov/ASS/internal/simulation"
+	"StantStantov/ASS/internal/simulation/pools"
+	"StantStantov/ASS/internal/simulation/responders"
+	"math"
+)
+
+const deviationEpsilon = 1e-9
+
+type Comparison struct {
+	Name      string
+	Label     string
+	Simulated float64
+	MMc       float64
+	MMcK      float64
+}
+
+type Analytical struct {
+	AdmittedRate float64
+	OfferedRate  float64
+	ServiceRate  float64
+	Servers      uint64
+	Capacity     uint64
+
+	MMc  queueing.Prediction
+	MMcK queueing.Prediction
+
+	Comparisons []Comparison
+}
+
+// M/M/c gets only the jobs admitted into the pool, merged duplicates never
+// need service. Every agent holds at most one job, so for M/M/c/K the amount
+// of agents bounds the jobs in the system, all jobs created are offered and
+// a merged duplicate plays the part of a lost arrival. Both models assume an
+// infinite source while the agents are finite, so an agent waiting for its
+// job sends no new jobs and the simulated waits come out shorter.
+func CollectAnalytical(summary Summary) Analytical {
+	analytical := Analytical{}
+
+	duration := summary.Duration
+	handled := uint64(0)
+	timeBusy := 0.0
+	timesBusy := responders.TimesBusy(simulation.RespondersSystem, simulation.RespondersSystem.Responders...)
+	for i, id := range simulation.RespondersSystem.Responders {
+		handled += simulation.RespondersSystem.Handled[id]
+		timeBusy += timesBusy[i]
+	}
+
+	if duration > 0 {
+		analytical.AdmittedRate = float64(summary.JobsCreated-summary.JobsDuplicated) / duration
+		analytical.OfferedRate = float64(summary.JobsCreated) / duration
+	}
+	if timeBusy > 0 {
+		analytical.ServiceRate = float64(handled) / timeBusy
+	}
+	analytical.Servers = uint64(len(simulation.RespondersSystem.Responders))
+	analytical.Capacity = uint64(len(simulation.AgentsSystem.AgentsIds))
+
+	analytical.MMc = queueing.MMc(analytical.AdmittedRate, analytical.ServiceRate, analytical.Servers)
+	analytical.MMcK = queueing.MMcK(analytical.OfferedRate, analytical.ServiceRate, analytical.Servers, analytical.Capacity)
+
+	utilisation := 0.0
+	queueLength := 0.0
+	if duration > 0 {
+		utilisation = timeBusy / (duration * float64(analytical.Servers))
+		queueLength = pools.WaitingAreaUntilNow(simulation.Pool) / duration
+	}
+	waitingTime := 0.0
+	if simulation.Pool.LockedAmount != 0 {
+		waitingTime = simulation.Pool.SpentTimeWaiting / float64(simulation.Pool.LockedAmount)
+	}
+
+	analytical.Comparisons = []Comparison{
+		newComparison("utilisation", "Загрузка приборов", utilisation, analytical, func(prediction queueing.Prediction) float64 {
+			return prediction.Utilisation
+		}),
+		newComparison("waiting_time", "Среднее время ожидания", waitingTime, analytical, func(prediction queueing.Prediction) float64 {
+			return prediction.WaitingTime
+		}),
+		newComparison("time_in_system", "Среднее время пребывания", summary.TimeInSystem, analytical, func(prediction queueing.Prediction) float64 {
+			return prediction.TimeInSystem
+		}),
+		newComparison("queue_length", "Средняя длина очереди", queueLength, analytical, func(prediction queueing.Prediction) float64 {
+			return prediction.QueueLength
+		}),
+		newComparison("loss_probability", "Вероятность потери", summary.DuplicatePercentage, analytical, func(prediction queueing.Prediction) float64 {
+			return prediction.LossProbability
+		}),
+	}
+
+	return analytical
+}
+
+func RelativeDeviation(simulated float64, predicted float64) float64 {
+	if math.Abs(predicted) < deviationEpsilon || math.IsNaN(predicted) {
+		return math.NaN()
+	}
+
+	return (simulated - predicted) / predicted
+}
+
+func newComparison(
+	name string,
+	label string,
+	simulated float64,
+	analytical Analytical,
+	value func(prediction queueing.Prediction) float64,
+) Comparison {
+	comparison := Comparison{
+		Name:      name,
+		Label:     label,
+		Simulated: simulated,
+		MMc:       math.NaN(),
+		MMcK:      math.NaN(),
+	}
+	if analytical.MMc.IsStable {
+		comparison.MMc = value(analytical.MMc)
+	}
+	if analytical.MMcK.IsStable {
+		comparison.MMcK = value(analytical.MMcK)
+	}
+
+	return comparison
+}
@@ -0,0 +1,146 @@
+package report
+
+import (
+	"StantStantov/ASS/internal/common/stats"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// Tolerance is the half-width, in units of the statistic, that is precise
+// enough whatever the mean is, so statistics with means near zero, like the
+// amount of rewritten alerts, still reach a relative target.
+type Statistic struct {
+	Name      string
+	Label     string
+	Tolerance float64
+	Value     func(summary Summary) float64
+}
+
+type Estimate struct {
+	Name      string  `json:"name"`
+	Label     string  `json:"label"`
+	Mean      float64 `json:"mean"`
+	Deviation float64 `json:"standard_deviation"`
+	HalfWidth float64 `json:"half_width"`
+	Lower     float64 `json:"lower"`
+	Upper     float64 `json:"upper"`
+
+	Tolerance float64 `json:"-"`
+}
+
+type Estimates struct {
+	Replications uint64     `json:"replications"`
+	Confidence   float64    `json:"confidence"`
+	Seeds        []uint64   `json:"seeds"`
+	Statistics   []Estimate `json:"statistics"`
+}
+
+var SummaryStatistics = []Statistic{
+	{"ticks", "Количество обновлений", 0, func(summary Summary) float64 { return float64(summary.Ticks) }},
+	{"duration", "Модельное время", 0, func(summary Summary) float64 { return summary.Duration }},
+	{"alerts_saved", "Количество сохраннёных тревог", 0.5, func(summary Summary) float64 { return float64(summary.AlertsSaved) }},
+	{"alerts_rewritten", "Количество перезаписанных тревог", 0.5, func(summary Summary) float64 { return float64(summary.AlertsRewritten) }},
+	{"alerts_spilled", "Количество тревог в общей области", 0.5, func(summary Summary) float64 { return float64(summary.AlertsSpilled) }},
+	{"rewrite_percentage", "Процент перезаписанных", 0.1, func(summary Summary) float64 { return summary.RewritePercentage }},
+	{"jobs_created", "Количество созданных задач", 0.5, func(summary Summary) float64 { return float64(summary.JobsCreated) }},
+	{"jobs_duplicated", "Количество задач-дупликатов", 0.5, func(summary Summary) float64 { return float64(summary.JobsDuplicated) }},
+	{"duplicate_percentage", "Процент дупликатов", 0.1, func(summary Summary) float64 { return summary.DuplicatePercentage }},
+	{"jobs_finished", "Количество завершенных задач", 0.5, func(summary Summary) float64 { return float64(summary.JobsFinished) }},
+	{"load_percentage", "Процент нагрузки", 0.1, func(summary Summary) float64 { return summary.LoadPercentage }},
+	{"time_in_system", "Среднее время пребывания в системе", 0, func(summary Summary) float64 { return summary.TimeInSystem }},
+}
+
+func EstimateSummaries(summaries []Summary, confidence float64) Estimates {
+	estimates := Estimates{
+		Replications: uint64(len(summaries)),
+		Confidence:   confidence,
+		Seeds:        make([]uint64, len(summaries)),
+		Statistics:   make([]Estimate, len(SummaryStatistics)),
+	}
+	for i, summary := range summaries {
+		estimates.Seeds[i] = summary.Seed
+	}
+
+	values := make([]float64, len(summaries))
+	for i, statistic := range SummaryStatistics {
+		for j, summary := range summaries {
+			values[j] = statistic.Value(summary)
+		}
+
+		mean := stats.Mean(values)
+		halfWidth := stats.StudentHalfWidth(values, confidence)
+		estimates.Statistics[i] = Estimate{
+			Name:      statistic.Name,
+			Label:     statistic.Label,
+			Mean:      mean,
+			Deviation: stats.StandardDeviation(values),
+			HalfWidth: halfWidth,
+			Lower:     mean - halfWidth,
+			Upper:     mean + halfWidth,
+
+			Tolerance: statistic.Tolerance,
+		}
+	}
+
+	return estimates
+}
+
+func IsPrecise(estimate Estimate, relativeHalfWidth float64) bool {
+	return estimate.HalfWidth <= max(relativeHalfWidth*math.Abs(estimate.Mean), estimate.Tolerance)
+}
+
+func WriteEstimates(output io.Writer, format string, estimates Estimates) error {
+	switch format {
+	case "json":
+		return WriteEstimatesJSON(output, estimates)
+	case "csv":
+		return writeCSVTables(output, estimatesTables(estimates))
+	case "markdown":
+		return writeMarkdownTables(output, estimatesTables(estimates))
+	}
+
+	return fmt.Errorf("unknown export format %q", format)
+}
+
+func WriteEstimatesJSON(output io.Writer, estimates Estimates) error {
+	encoder := json.NewEncoder(output)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(estimates)
+}
+
+func estimatesTables(estimates Estimates) []exportTable {
+	seeds := make([]string, len(estimates.Seeds))
+	for i, seed := range estimates.Seeds {
+		seeds[i] = formatUnsigned(seed)
+	}
+	general := exportTable{
+		Title:  "Общая статистика",
+		Header: []string{"key", "value"},
+		Rows: [][]string{
+			{"replications", formatUnsigned(estimates.Replications)},
+			{"confidence", formatFloat(estimates.Confidence)},
+			{"seeds", strings.Join(seeds, " ")},
+		},
+	}
+
+	statistics := exportTable{
+		Title:  "Доверительные интервалы",
+		Header: []string{"name", "mean", "standard_deviation", "half_width", "lower", "upper"},
+	}
+	for _, estimate := range estimates.Statistics {
+		statistics.Rows = append(statistics.Rows, []string{
+			estimate.Name,
+			formatFloat(estimate.Mean),
+			formatFloat(estimate.Deviation),
+			formatFloat(estimate.HalfWidth),
+			formatFloat(estimate.Lower),
+			formatFloat(estimate.Upper),
+		})
+	}
+
+	return []exportTable{general, statistics}
+}
@@ -0,0 +1,326 @@
+package report
+
+import (
+	"StantStantov/ASS/internal/config"
+	"StantStantov/ASS/internal/simulation"
+	"StantStantov/ASS/internal/simulation/responders"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/StantStantov/rps/swamp/collections/sparsemap"
+)
+
+type Metadata struct {
+	Config   *config.Config `json:"config"`
+	Seed     uint64         `json:"seed"`
+	Ticks    uint64         `json:"ticks"`
+	Duration float64        `json:"duration"`
+}
+
+type SourceRow struct {
+	Id           uint64  `json:"id"`
+	Created      uint64  `json:"created"`
+	Rewritten    uint64  `json:"rewritten"`
+	TimeInPool   float64 `json:"time_in_pool"`
+	TimeHandling float64 `json:"time_handling"`
+}
+
+type ResponderRow struct {
+	Id           uint64  `json:"id"`
+	Priority     uint64  `json:"priority"`
+	Handled      uint64  `json:"handled"`
+	HandledShare float64 `json:"handled_share"`
+	TimeHandling float64 `json:"time_handling"`
+	Utilisation  float64 `json:"utilisation"`
+}
+
+type PriorityRow struct {
+	Priority     uint64  `json:"priority"`
+	Responders   uint64  `json:"responders"`
+	Handled      uint64  `json:"handled"`
+	HandledShare float64 `json:"handled_share"`
+	Utilisation  float64 `json:"utilisation"`
+}
+
+type Export struct {
+	Metadata   Metadata       `json:"metadata"`
+	Summary    Summary        `json:"summary"`
+	Sources    []SourceRow    `json:"sources"`
+	Responders []ResponderRow `json:"responders"`
+	Priorities []PriorityRow  `json:"priorities"`
+}
+
+func CollectExport() Export {
+	export := Export{}
+
+	export.Summary = CollectSummary()
+	export.Metadata = Metadata{
+		Config:   simulation.Config,
+		Seed:     export.Summary.Seed,
+		Ticks:    export.Summary.Ticks,
+		Duration: export.Summary.Duration,
+	}
+	export.Sources = collectSources()
+	export.Responders = collectResponders(export.Summary)
+	export.Priorities = collectPriorities(export.Summary)
+
+	return export
+}
+
+func WriteExport(output io.Writer, format string, export Export) error {
+	switch format {
+	case "json":
+		return WriteJSON(output, export)
+	case "csv":
+		return WriteCSV(output, export)
+	case "markdown":
+		return WriteMarkdown(output, export)
+	}
+
+	return fmt.Errorf("unknown export format %q", format)
+}
+
+func WriteJSON(output io.Writer, export Export) error {
+	encoder := json.NewEncoder(output)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(export)
+}
+
+func WriteCSV(output io.Writer, export Export) error {
+	return writeCSVTables(output, exportTables(export))
+}
+
+func WriteMarkdown(output io.Writer, export Export) error {
+	return writeMarkdownTables(output, exportTables(export))
+}
+
+type exportTable struct {
+	Title  string
+	Header []string
+	Rows   [][]string
+}
+
+// writeCSVTables writes the tables one after another, each with its own
+// header and separated by an empty line.
+func writeCSVTables(output io.Writer, tables []exportTable) error {
+	writer := csv.NewWriter(output)
+	for i, table := range tables {
+		if i != 0 {
+			writer.Write([]string{})
+		}
+		writer.Write(table.Header)
+		writer.WriteAll(table.Rows)
+	}
+	writer.Flush()
+
+	return writer.Error()
+}
+
+func writeMarkdownTables(output io.Writer, tables []exportTable) error {
+	for i, table := range tables {
+		if i != 0 {
+			fmt.Fprint(output, "\n")
+		}
+		fmt.Fprintf(output, "## %s\n\n", table.Title)
+		writeMarkdownRow(output, table.Header)
+		separators := make([]string, len(table.Header))
+		for j := range separators {
+			separators[j] = "---"
+		}
+		writeMarkdownRow(output, separators)
+		for _, row := range table.Rows {
+			writeMarkdownRow(output, row)
+		}
+	}
+
+	return nil
+}
+
+func exportTables(export Export) []exportTable {
+	configData, _ := json.Marshal(export.Metadata.Config)
+	general := exportTable{
+		Title:  "Общая статистика",
+		Header: []string{"key", "value"},
+		Rows: [][]string{
+			{"config", string(configData)},
+			{"seed", formatUnsigned(export.Metadata.Seed)},
+			{"ticks", formatUnsigned(export.Metadata.Ticks)},
+			{"duration", formatFloat(export.Metadata.Duration)},
+		},
+	}
+	summary := reflect.ValueOf(export.Summary)
+	for i := range summary.NumField() {
+		name := summary.Type().Field(i).Tag.Get("json")
+		if slices.Contains([]string{"seed", "ticks", "duration"}, name) {
+			continue
+		}
+		general.Rows = append(general.Rows, []string{name, fmt.Sprint(summary.Field(i).Interface())})
+	}
+
+	sources := exportTable{
+		Title:  "Статистика по источникам",
+		Header: []string{"id", "created", "rewritten", "time_in_pool", "time_handling"},
+	}
+	for _, row := range export.Sources {
+		sources.Rows = append(sources.Rows, []string{
+			formatUnsigned(row.Id),
+			formatUnsigned(row.Created),
+			formatUnsigned(row.Rewritten),
+			formatFloat(row.TimeInPool),
+			formatFloat(row.TimeHandling),
+		})
+	}
+
+	responders := exportTable{
+		Title:  "Статистика по приборам",
+		Header: []string{"id", "priority", "handled", "handled_share", "time_handling", "utilisation"},
+	}
+	for _, row := range export.Responders {
+		responders.Rows = append(responders.Rows, []string{
+			formatUnsigned(row.Id),
+			formatUnsigned(row.Priority),
+			formatUnsigned(row.Handled),
+			formatFloat(row.HandledShare),
+			formatFloat(row.TimeHandling),
+			formatFloat(row.Utilisation),
+		})
+	}
+
+	priorities := exportTable{
+		Title:  "Статистика по приоритетам",
+		Header: []string{"priority", "responders", "handled", "handled_share", "utilisation"},
+	}
+	for _, row := range export.Priorities {
+		priorities.Rows = append(priorities.Rows, []string{
+			formatUnsigned(row.Priority),
+			formatUnsigned(row.Responders),
+			formatUnsigned(row.Handled),
+			formatFloat(row.HandledShare),
+			formatFloat(row.Utilisation),
+		})
+	}
+
+	return []exportTable{general, sources, responders, priorities}
+}
+
+func collectSources() []SourceRow {
+	ids := simulation.AgentsSystem.AgentsIds
+	timesSpentInPool := make([]float64, len(ids))
+	gotTimesSpentInPool := make([]bool, len(ids))
+	timesSpentInPool, gotTimesSpentInPool = sparsemap.GetFromSparseMap(simulation.Pool.TimeLocked, timesSpentInPool, gotTimesSpentInPool, ids...)
+	timesSpentHandling := make([]float64, len(ids))
+	gotTimesSpentHandling := make([]bool, len(ids))
+	timesSpentHandling, gotTimesSpentHandling = sparsemap.GetFromSparseMap(simulation.Pool.TimeUnlocked, timesSpentHandling, gotTimesSpentHandling, ids...)
+
+	rows := make([]SourceRow, len(ids))
+	for i, id := range ids {
+		rows[i] = SourceRow{
+			Id:           id,
+			Created:      simulation.AgentsSystem.Created[id],
+			Rewritten:    simulation.Buffer.Rewritten[id],
+			TimeInPool:   timesSpentInPool[i],
+			TimeHandling: timesSpentHandling[i],
+		}
+	}
+
+	return rows
+}
+
+func collectResponders(summary Summary) []ResponderRow {
+	ids := simulation.RespondersSystem.Responders
+	timesSpentHandling := make([]float64, len(ids))
+	gotTimesSpentHandling := make([]bool, len(ids))
+	timesSpentHandling, gotTimesSpentHandling = sparsemap.GetFromSparseMap(simulation.RespondersSystem.TimeUnlocked, timesSpentHandling, gotTimesSpentHandling, ids...)
+	timesBusy := responders.TimesBusy(simulation.RespondersSystem, ids...)
+
+	rows := make([]ResponderRow, len(ids))
+	for i, id := range ids {
+		handled := simulation.RespondersSystem.Handled[id]
+		handledShare := float64(0)
+		if handled != 0 && summary.JobsFinished != 0 {
+			handledShare = float64(handled) / float64(summary.JobsFinished)
+		}
+		utilisation := float64(0)
+		if summary.Duration != 0 {
+			utilisation = timesBusy[i] / summary.Duration
+		}
+
+		rows[i] = ResponderRow{
+			Id:           id,
+			Priority:     simulation.RespondersSystem.RespondersInfo[id].Priority,
+			Handled:      handled,
+			HandledShare: handledShare,
+			TimeHandling: timesSpentHandling[i],
+			Utilisation:  utilisation,
+		}
+	}
+
+	return rows
+}
+
+func collectPriorities(summary Summary) []PriorityRow {
+	priorities := []uint64{}
+	respondersByPriority := map[uint64]uint64{}
+	handledByPriority := map[uint64]uint64{}
+	timeBusyByPriority := map[uint64]float64{}
+	timesBusy := responders.TimesBusy(simulation.RespondersSystem, simulation.RespondersSystem.Responders...)
+	for i, id := range simulation.RespondersSystem.Responders {
+		priority := simulation.RespondersSystem.RespondersInfo[id].Priority
+		if _, ok := respondersByPriority[priority]; !ok {
+			priorities = append(priorities, priority)
+		}
+
+		respondersByPriority[priority]++
+		handledByPriority[priority] += simulation.RespondersSystem.Handled[id]
+		timeBusyByPriority[priority] += timesBusy[i]
+	}
+	slices.Sort(priorities)
+	slices.Reverse(priorities)
+
+	rows := make([]PriorityRow, len(priorities))
+	for i, priority := range priorities {
+		respondersAmount := respondersByPriority[priority]
+		handled := handledByPriority[priority]
+		handledShare := float64(0)
+		if handled != 0 && summary.JobsFinished != 0 {
+			handledShare = float64(handled) / float64(summary.JobsFinished)
+		}
+		utilisation := float64(0)
+		if summary.Duration != 0 {
+			utilisation = timeBusyByPriority[priority] / (summary.Duration * float64(respondersAmount))
+		}
+
+		rows[i] = PriorityRow{
+			Priority:     priority,
+			Responders:   respondersAmount,
+			Handled:      handled,
+			HandledShare: handledShare,
+			Utilisation:  utilisation,
+		}
+	}
+
+	return rows
+}
+
+func writeMarkdownRow(output io.Writer, cells []string) {
+	fmt.Fprint(output, "|")
+	for _, cell := range cells {
+		fmt.Fprintf(output, " %s |", strings.ReplaceAll(cell, "|", `\|`))
+	}
+	fmt.Fprint(output, "\n")
+}
+
+func formatUnsigned(value uint64) string {
+	return strconv.FormatUint(value, 10)
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,77 @@
+package report
+
+import (
+	"StantStantov/ASS/internal/simulation"
+	"StantStantov/ASS/internal/simulation/metrics"
+	"StantStantov/ASS/internal/simulation/responders"
+
+	"github.com/StantStantov/rps/swamp/atomic"
+)
+
+type Summary struct {
+	Seed     uint64  `json:"seed"`
+	Ticks    uint64  `json:"ticks"`
+	Duration float64 `json:"duration"`
+
+	WarmupTicks uint64  `json:"warmup_ticks"`
+	WarmupTime  float64 `json:"warmup_time"`
+
+	AlertsSaved       uint64  `json:"alerts_saved"`
+	AlertsRewritten   uint64  `json:"alerts_rewritten"`
+	AlertsSpilled     uint64  `json:"alerts_spilled"`
+	RewritePercentage float64 `json:"rewrite_percentage"`
+
+	JobsCreated         uint64  `json:"jobs_created"`
+	JobsDuplicated      uint64  `json:"jobs_duplicated"`
+	DuplicatePercentage float64 `json:"duplicate_percentage"`
+
+	JobsFinished   uint64  `json:"jobs_finished"`
+	LoadPercentage float64 `json:"load_percentage"`
+	TimeInSystem   float64 `json:"time_in_system"`
+}
+
+func CollectSummary() Summary {
+	summary := Summary{}
+
+	summary.Seed = simulation.Seed
+	summary.Ticks = simulation.TickCounter
+	summary.Duration = metrics.ElapsedSeconds(simulation.MetricsSystem)
+
+	summary.WarmupTicks = simulation.WarmupSystem.EndedAtTick
+	summary.WarmupTime = simulation.WarmupSystem.EndedAt
+
+	summary.AlertsSaved = loadMetric(metrics.AlertsBufferedCounter)
+	summary.AlertsRewritten = loadMetric(metrics.AlertsRewrittenCounter)
+	summary.AlertsSpilled = loadMetric(metrics.AlertsSpilledCounter)
+	if summary.AlertsRewritten != 0 {
+		summary.RewritePercentage = float64(summary.AlertsRewritten) / float64(summary.AlertsSaved)
+	}
+
+	addedJobs := loadMetric(metrics.JobsPendingCounter)
+	summary.JobsDuplicated = loadMetric(metrics.JobsSkippedCounter)
+	summary.JobsCreated = addedJobs + summary.JobsDuplicated
+	if summary.JobsDuplicated != 0 {
+		summary.DuplicatePercentage = float64(summary.JobsDuplicated) / float64(summary.JobsCreated)
+	}
+
+	summary.JobsFinished = loadMetric(metrics.JobsUnlockedCounter)
+	timeBusy := 0.0
+	for _, timeBusyOfResponder := range responders.TimesBusy(simulation.RespondersSystem, simulation.RespondersSystem.Responders...) {
+		timeBusy += timeBusyOfResponder
+	}
+	respondersAmount := float64(len(simulation.RespondersSystem.Responders))
+	if summary.Duration > 0 && respondersAmount != 0 {
+		summary.LoadPercentage = timeBusy / (summary.Duration * respondersAmount)
+	}
+	if simulation.Pool.SpentTimeInPool != 0 {
+		summary.TimeInSystem = simulation.Pool.SpentTimeInPool / float64(simulation.Pool.PoppedAmount)
+	}
+
+	return summary
+}
+
+func loadMetric(metric metrics.MetricType) uint64 {
+	atomicValue := &simulation.MetricsSystem.Metrics[metric]
+
+	return atomic.LoadUint64(atomicValue)
+}
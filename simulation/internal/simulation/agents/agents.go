@@ -1,10 +1,13 @@
 package agents
 
 import (
+	"StantStantov/ASS/internal/simulation/clock"
 	"StantStantov/ASS/internal/simulation/dispatchers"
 	"StantStantov/ASS/internal/simulation/metrics"
 	"StantStantov/ASS/internal/simulation/models"
-	"math/rand"
+	"StantStantov/ASS/internal/simulation/random"
+	"math"
+	"slices"
 
 	"github.com/StantStantov/rps/swamp/behaivors/buffers"
 	"github.com/StantStantov/rps/swamp/bools"
@@ -13,24 +16,62 @@ import (
 	"github.com/StantStantov/rps/swamp/logging/logfmt"
 )
 
+type ArrivalModel uint8
+
+const (
+	BernoulliArrival ArrivalModel = iota
+	PoissonArrival
+	DeterministicArrival
+)
+
+const phaseTolerance = 1e-9
+
+var ArrivalModelsNames = []string{
+	"bernoulli",
+	"poisson",
+	"deterministic",
+}
+
+func ArrivalModelFromName(name string) (ArrivalModel, bool) {
+	index := slices.Index(ArrivalModelsNames, name)
+	if index < 0 {
+		return BernoulliArrival, false
+	}
+
+	return ArrivalModel(index), true
+}
+
 type AgentSystem struct {
 	AgentsIds        []models.AgentId
 	MinChanceToCrash float32
+	ArrivalModel     ArrivalModel
+	Rates            []float64
 
-	Silent  []models.AgentId
-	Alarmed []models.AgentId
-	Created []uint64
+	Silent   []models.AgentId
+	Alarmed  []models.AgentId
+	Created  []uint64
+	Rejected []uint64
+	Phases   []float64
+	PolledAt float64
 
 	Dispatcher *dispatchers.DispatchSystem
 
-	Metrics *metrics.MetricsSystem
-	Logger  *logging.Logger
+	Clock            *clock.ClockSystem
+	Random           *random.Generator
+	SeveritiesRandom *random.Generator
+	Metrics          *metrics.MetricsSystem
+	Logger           *logging.Logger
 }
 
 func NewAgentSystem(
 	capacity uint64,
 	minChanceToCrash float32,
+	arrivalModel ArrivalModel,
+	rates []float64,
 	dispatcher *dispatchers.DispatchSystem,
+	clockSystem *clock.ClockSystem,
+	random *random.Generator,
+	severitiesRandom *random.Generator,
 	metrics *metrics.MetricsSystem,
 	logger *logging.Logger,
 ) *AgentSystem {
@@ -41,13 +82,22 @@ func NewAgentSystem(
 		system.AgentsIds[i] = models.AgentId(i)
 	}
 	system.MinChanceToCrash = minChanceToCrash
+	system.ArrivalModel = arrivalModel
+	system.Rates = make([]float64, capacity)
+	copy(system.Rates, rates)
 
 	system.Silent = []models.AgentId{}
 	system.Alarmed = []models.AgentId{}
 	system.Created = make([]uint64, capacity)
+	system.Rejected = make([]uint64, capacity)
+	system.Phases = make([]float64, capacity)
+	system.PolledAt = clock.Now(clockSystem)
 
 	system.Dispatcher = dispatcher
 
+	system.Clock = clockSystem
+	system.Random = random
+	system.SeveritiesRandom = severitiesRandom
 	system.Metrics = metrics
 	system.Logger = logging.NewChildLogger(logger, func(event *logging.Event) {
 		logfmt.String(event, "from", "agent_system")
@@ -57,11 +107,42 @@ func NewAgentSystem(
 }
 
 func ProcessAgentSystem(system *AgentSystem) {
-	areAlarmed := make([]bool, len(system.AgentsIds))
-	for i := range areAlarmed {
-		currentChance := rand.Float32()
-		alarmed := currentChance > system.MinChanceToCrash
-		areAlarmed[i] = alarmed
+	now := clock.Now(system.Clock)
+	elapsed := now - system.PolledAt
+	system.PolledAt = now
+
+	alertsAmounts := make([]uint64, len(system.AgentsIds))
+	switch system.ArrivalModel {
+	case BernoulliArrival:
+		for i := range alertsAmounts {
+			currentChance := system.Random.Rand.Float32()
+			if currentChance > system.MinChanceToCrash {
+				alertsAmounts[i] = 1
+			}
+		}
+	case PoissonArrival:
+		for i, id := range system.AgentsIds {
+			lambda := system.Rates[id] * elapsed
+			alertsAmounts[i] = random.Poisson(system.Random, lambda)
+		}
+	case DeterministicArrival:
+		for i, id := range system.AgentsIds {
+			phase := system.Phases[id] + system.Rates[id]*elapsed
+			alertsAmount := math.Floor(phase + phaseTolerance)
+			system.Phases[id] = max(phase-alertsAmount, 0)
+			alertsAmounts[i] = uint64(alertsAmount)
+		}
+	}
+
+	EmitAlerts(system, system.AgentsIds, alertsAmounts)
+}
+
+func EmitAlerts(system *AgentSystem, ids []models.AgentId, alertsAmounts []uint64) {
+	now := clock.Now(system.Clock)
+
+	areAlarmed := make([]bool, len(ids))
+	for i, alertsAmount := range alertsAmounts {
+		areAlarmed[i] = alertsAmount > 0
 	}
 
 	alarmedAmount, silentAmount := bools.CountBools[uint64, uint64](areAlarmed...)
@@ -69,17 +150,35 @@ func ProcessAgentSystem(system *AgentSystem) {
 	alarmedAgents := make([]models.AgentId, alarmedAmount)
 	silentBuffer := &buffers.SetBuffer[models.AgentId, uint64]{Array: silentAgents}
 	alarmedBuffer := &buffers.SetBuffer[models.AgentId, uint64]{Array: alarmedAgents}
-	filters.SeparateByBools(silentBuffer, alarmedBuffer, system.AgentsIds, areAlarmed)
+	filters.SeparateByBools(silentBuffer, alarmedBuffer, ids, areAlarmed)
+
+	alarmedAlertsAmounts := make([]uint64, alarmedAmount)
+	silentAlertsAmountsBuffer := &buffers.SetBuffer[uint64, uint64]{Array: make([]uint64, silentAmount)}
+	alarmedAlertsAmountsBuffer := &buffers.SetBuffer[uint64, uint64]{Array: alarmedAlertsAmounts}
+	filters.SeparateByBools(silentAlertsAmountsBuffer, alarmedAlertsAmountsBuffer, alertsAmounts, areAlarmed)
 
 	alerts := make([][]models.MachineInfo, len(alarmedAgents))
 	for i, id := range alarmedAgents {
-		machineInfo := models.MachineInfo{Id: id}
-
-		alerts[i] = []models.MachineInfo{machineInfo}
-		system.Created[id]++
+		alertsAmount := alarmedAlertsAmounts[i]
+		alerts[i] = make([]models.MachineInfo, alertsAmount)
+		for j := range alerts[i] {
+			alerts[i][j] = models.MachineInfo{
+				Id:        id,
+				Severity:  uint8(1 + system.SeveritiesRandom.Rand.IntN(models.MaxSeverity)),
+				CreatedAt: now,
+			}
+		}
+
+		system.Created[id] += alertsAmount
 	}
 
-	dispatchers.SaveAlerts(system.Dispatcher, alarmedAgents, alerts)
+	areRejected := dispatchers.SaveAlerts(system.Dispatcher, alarmedAgents, alerts)
+	for i, rejected := range areRejected {
+		if rejected {
+			id := alarmedAgents[i]
+			system.Rejected[id] += uint64(len(alerts[i]))
+		}
+	}
 
 	system.Silent = silentAgents
 	system.Alarmed = alarmedAgents
@@ -98,3 +197,24 @@ func ProcessAgentSystem(system *AgentSystem) {
 		},
 	)
 }
+
+func ResetStatistics(system *AgentSystem) {
+	clear(system.Created)
+	clear(system.Rejected)
+}
+
+func NextArrivalDelay(system *AgentSystem, id models.AgentId) float64 {
+	rate := system.Rates[id]
+	if rate <= 0 {
+		return math.Inf(1)
+	}
+
+	switch system.ArrivalModel {
+	case PoissonArrival:
+		return random.Exponential(system.Random, rate)
+	case DeterministicArrival:
+		return 1 / rate
+	}
+
+	return math.Inf(1)
+}
@@ -0,0 +1,56 @@
+package agents
+
+import (
+	"StantStantov/ASS/internal/simulation/models"
+	"StantStantov/ASS/internal/simulation/random"
+	"fmt"
+	"slices"
+)
+
+type Snapshot struct {
+	Silent   []models.AgentId `json:"silent"`
+	Alarmed  []models.AgentId `json:"alarmed"`
+	Created  []uint64         `json:"created"`
+	Rejected []uint64         `json:"rejected"`
+	Phases   []float64        `json:"phases"`
+	PolledAt float64          `json:"polled_at"`
+	Random   []byte           `json:"random"`
+
+	SeveritiesRandom []byte `json:"severities_random"`
+}
+
+func TakeSnapshot(system *AgentSystem) Snapshot {
+	return Snapshot{
+		Silent:   slices.Clone(system.Silent),
+		Alarmed:  slices.Clone(system.Alarmed),
+		Created:  slices.Clone(system.Created),
+		Rejected: slices.Clone(system.Rejected),
+		Phases:   slices.Clone(system.Phases),
+		PolledAt: system.PolledAt,
+		Random:   random.MarshalState(system.Random),
+
+		SeveritiesRandom: random.MarshalState(system.SeveritiesRandom),
+	}
+}
+
+func ValidateSnapshot(saved Snapshot) error {
+	if err := random.ValidateState(saved.Random); err != nil {
+		return fmt.Errorf("agents random: %w", err)
+	}
+	if err := random.ValidateState(saved.SeveritiesRandom); err != nil {
+		return fmt.Errorf("agents severities_random: %w", err)
+	}
+
+	return nil
+}
+
+func RestoreSnapshot(system *AgentSystem, saved Snapshot) {
+	system.Silent = slices.Clone(saved.Silent)
+	system.Alarmed = slices.Clone(saved.Alarmed)
+	copy(system.Created, saved.Created)
+	copy(system.Rejected, saved.Rejected)
+	copy(system.Phases, saved.Phases)
+	system.PolledAt = saved.PolledAt
+	random.UnmarshalState(system.Random, saved.Random)
+	random.UnmarshalState(system.SeveritiesRandom, saved.SeveritiesRandom)
+}
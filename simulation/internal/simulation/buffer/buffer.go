@@ -4,6 +4,7 @@ import (
 	"StantStantov/ASS/internal/simulation/metrics"
 	"StantStantov/ASS/internal/simulation/models"
 	"fmt"
+	"slices"
 	"sync"
 
 	"github.com/StantStantov/rps/swamp/behaivors/buffers"
@@ -16,6 +17,10 @@ import (
 type BufferSystem struct {
 	Values         *sparsemap.SparseMap[uint64, buffers.SetBuffer[models.MachineInfo, uint64]]
 	AlertsCapacity uint64
+	RefusalPolicy  RefusalPolicy
+
+	Overflow         []models.MachineInfo
+	OverflowCapacity uint64
 
 	Rewritten []uint64
 
@@ -28,6 +33,8 @@ type BufferSystem struct {
 func NewBufferSystem(
 	capacity uint64,
 	alertsCapacity uint64,
+	refusalPolicy RefusalPolicy,
+	overflowCapacity uint64,
 	metrics *metrics.MetricsSystem,
 	logger *logging.Logger,
 ) *BufferSystem {
@@ -35,6 +42,10 @@ func NewBufferSystem(
 
 	system.Values = sparsemap.NewSparseMap[uint64, buffers.SetBuffer[models.MachineInfo, uint64]](capacity)
 	system.AlertsCapacity = alertsCapacity
+	system.RefusalPolicy = refusalPolicy
+
+	system.Overflow = make([]models.MachineInfo, 0, overflowCapacity)
+	system.OverflowCapacity = overflowCapacity
 
 	system.Rewritten = make([]uint64, capacity)
 
@@ -48,11 +59,12 @@ func NewBufferSystem(
 	return system
 }
 
-func AddIntoBuffer(system *BufferSystem, ids []models.AgentId, alertsBatches [][]models.MachineInfo) {
+func AddIntoBuffer(system *BufferSystem, ids []models.AgentId, alertsBatches [][]models.MachineInfo) []bool {
 	system.Mutex.Lock()
 	defer system.Mutex.Unlock()
 
 	alertsAdded := uint64(0)
+	alertsSpilled := uint64(0)
 	alertsSkipped := uint64(0)
 
 	minLength := min(len(ids), len(alertsBatches))
@@ -62,31 +74,30 @@ func AddIntoBuffer(system *BufferSystem, ids []models.AgentId, alertsBatches [][
 
 	iterNewValues := bools.IterOnlyFalse[uint64](arePresent...)
 	for i := range iterNewValues {
-		alerts := alertsBatches[i]
-
 		bufferNew := &alertBuffers[i]
 		bufferNew.Array = make([]models.MachineInfo, system.AlertsCapacity)
-		for _, alert := range alerts {
-			buffers.AppendToSetBuffer(bufferNew, alert)
-
-			alertsAdded++
-		}
 	}
 
-	iterOldValues := bools.IterOnlyTrue[uint64](arePresent...)
-	for i := range iterOldValues {
+	batchSizes := make([]float64, minLength)
+	areRejected := make([]bool, minLength)
+	refusedIds := []models.AgentId{}
+	refusedAmounts := []uint64{}
+	for i := range minLength {
 		id := ids[i]
 		alerts := alertsBatches[i]
-
-		bufferOld := &alertBuffers[i]
-		for _, alert := range alerts {
-			if bufferOld.Length != uint64(len(bufferOld.Array)) {
-				buffers.AppendToSetBuffer(bufferOld, alert)
-				alertsAdded++
-			} else {
-				alertsSkipped++
-				system.Rewritten[id]++
-			}
+		batchSizes[i] = float64(len(alerts))
+
+		fitCapacity(system, &alertBuffers[i])
+		result := addAlerts(system, &alertBuffers[i], alerts)
+		alertsAdded += result.Added
+		alertsSpilled += result.Spilled
+		alertsSkipped += result.Refused
+		areRejected[i] = result.Rejected
+
+		if result.Refused != 0 {
+			system.Rewritten[id] += result.Refused
+			refusedIds = append(refusedIds, id)
+			refusedAmounts = append(refusedAmounts, result.Refused)
 		}
 	}
 
@@ -98,6 +109,9 @@ func AddIntoBuffer(system *BufferSystem, ids []models.AgentId, alertsBatches [][
 
 	metrics.AddToMetric(system.Metrics, metrics.AlertsBufferedCounter, alertsAdded)
 	metrics.AddToMetric(system.Metrics, metrics.AlertsRewrittenCounter, alertsSkipped)
+	metrics.AddToMetric(system.Metrics, metrics.AlertsSpilledCounter, alertsSpilled)
+	metrics.AddToMetric(system.Metrics, RefusalPoliciesMetrics[system.RefusalPolicy], alertsSkipped)
+	metrics.ObserveHistogram(system.Metrics, metrics.BatchSizeHistogram, batchSizes...)
 
 	logging.GetThenSendInfo(
 		system.Logger,
@@ -114,19 +128,42 @@ func AddIntoBuffer(system *BufferSystem, ids []models.AgentId, alertsBatches [][
 			return nil
 		},
 	)
+
+	if len(refusedIds) != 0 {
+		logging.GetThenSendInfo(
+			system.Logger,
+			"refused alerts in buffer",
+			func(event *logging.Event, level logging.Level) error {
+				logfmt.String(event, "alerts.refusal_policy", RefusalPoliciesNames[system.RefusalPolicy])
+				logfmt.Unsigneds(event, "jobs.ids", refusedIds...)
+				logfmt.Unsigneds(event, "jobs.alerts.refused_amounts", refusedAmounts...)
+
+				return nil
+			},
+		)
+	}
+
+	return areRejected
 }
 
 func GetMultipleFromBuffer(system *BufferSystem, setBuffer *buffers.SetBuffer[[]models.MachineInfo, uint64], ids ...uint64) {
 	system.Mutex.Lock()
 	defer system.Mutex.Unlock()
 
-	oksGet := make([]bool, cap(setBuffer.Array))
-	alertBuffers := make([]buffers.SetBuffer[models.MachineInfo, uint64], cap(setBuffer.Array))
+	oksGet := make([]bool, len(ids))
+	alertBuffers := make([]buffers.SetBuffer[models.MachineInfo, uint64], len(ids))
 	alertBuffers, oksGet = sparsemap.GetFromSparseMap(system.Values, alertBuffers, oksGet, ids...)
 
 	for i := range alertBuffers {
 		alertBuffer := &alertBuffers[i]
 		alerts := buffers.ValuesOfSetBuffer(alertBuffer)
+		if system.RefusalPolicy == SpillRefusal {
+			spilled := takeSpilled(system, ids[i])
+			if len(spilled) != 0 {
+				alerts = append(slices.Clone(alerts), spilled...)
+			}
+		}
+
 		buffers.AppendToSetBuffer(setBuffer, alerts)
 	}
 
@@ -180,3 +217,30 @@ func ResetAlertsInBuffer(system *BufferSystem, ids ...uint64) {
 		},
 	)
 }
+
+func SetAlertsCapacity(system *BufferSystem, alertsCapacity uint64) {
+	system.Mutex.Lock()
+	defer system.Mutex.Unlock()
+
+	system.AlertsCapacity = alertsCapacity
+}
+
+// Buffers holding more alerts than the capacity keep them and are fitted
+// after they are reset.
+func fitCapacity(system *BufferSystem, alertsBuffer *buffers.SetBuffer[models.MachineInfo, uint64]) {
+	capacity := system.AlertsCapacity
+	if uint64(len(alertsBuffer.Array)) == capacity || alertsBuffer.Length > capacity {
+		return
+	}
+
+	array := make([]models.MachineInfo, capacity)
+	copy(array, buffers.ValuesOfSetBuffer(alertsBuffer))
+	alertsBuffer.Array = array
+}
+
+func ResetStatistics(system *BufferSystem) {
+	system.Mutex.Lock()
+	defer system.Mutex.Unlock()
+
+	clear(system.Rewritten)
+}
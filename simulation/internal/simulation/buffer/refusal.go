@@ -0,0 +1,103 @@
+package buffer
+
+import (
+	"StantStantov/ASS/internal/simulation/metrics"
+	"StantStantov/ASS/internal/simulation/models"
+	"slices"
+
+	"github.com/StantStantov/rps/swamp/behaivors/buffers"
+)
+
+type RefusalPolicy uint8
+
+const (
+	DropNewestRefusal RefusalPolicy = iota
+	OverwriteOldestRefusal
+	RejectBatchRefusal
+	SpillRefusal
+)
+
+var RefusalPoliciesNames = []string{
+	"drop_newest",
+	"overwrite_oldest",
+	"reject_batch",
+	"spill",
+}
+
+var RefusalPoliciesMetrics = []metrics.MetricType{
+	metrics.AlertsDroppedCounter,
+	metrics.AlertsOverwrittenCounter,
+	metrics.AlertsRejectedCounter,
+	metrics.AlertsSpillDroppedCounter,
+}
+
+func RefusalPolicyFromName(name string) (RefusalPolicy, bool) {
+	index := slices.Index(RefusalPoliciesNames, name)
+	if index < 0 {
+		return DropNewestRefusal, false
+	}
+
+	return RefusalPolicy(index), true
+}
+
+type addResult struct {
+	Added    uint64
+	Spilled  uint64
+	Refused  uint64
+	Rejected bool
+}
+
+func addAlerts(system *BufferSystem, alertsBuffer *buffers.SetBuffer[models.MachineInfo, uint64], alerts []models.MachineInfo) addResult {
+	result := addResult{}
+
+	capacity := uint64(len(alertsBuffer.Array))
+	if system.RefusalPolicy == RejectBatchRefusal && alertsBuffer.Length+uint64(len(alerts)) > capacity {
+		result.Refused = uint64(len(alerts))
+		result.Rejected = true
+
+		return result
+	}
+
+	for _, alert := range alerts {
+		if alertsBuffer.Length != capacity {
+			buffers.AppendToSetBuffer(alertsBuffer, alert)
+			result.Added++
+
+			continue
+		}
+
+		switch system.RefusalPolicy {
+		case OverwriteOldestRefusal:
+			copy(alertsBuffer.Array, alertsBuffer.Array[1:])
+			alertsBuffer.Array[capacity-1] = alert
+			result.Added++
+			result.Refused++
+		case SpillRefusal:
+			if uint64(len(system.Overflow)) < system.OverflowCapacity {
+				system.Overflow = append(system.Overflow, alert)
+				result.Spilled++
+			} else {
+				result.Refused++
+			}
+		default:
+			result.Refused++
+		}
+	}
+
+	return result
+}
+
+func takeSpilled(system *BufferSystem, id models.AgentId) []models.MachineInfo {
+	spilled := []models.MachineInfo{}
+	kept := system.Overflow[:0]
+	for _, alert := range system.Overflow {
+		if alert.Id == id {
+			spilled = append(spilled, alert)
+		} else {
+			kept = append(kept, alert)
+		}
+	}
+	system.Overflow = kept
+
+	return spilled
+}
@@ -0,0 +1,70 @@
+package buffer
+
+import (
+	"StantStantov/ASS/internal/simulation/models"
+	"StantStantov/ASS/internal/simulation/snapshot"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/StantStantov/rps/swamp/behaivors/buffers"
+)
+
+type Snapshot struct {
+	Values    snapshot.Entries[[]models.MachineInfo] `json:"values"`
+	Overflow  []models.MachineInfo                   `json:"overflow"`
+	Rewritten []uint64                               `json:"rewritten"`
+}
+
+func TakeSnapshot(system *BufferSystem) Snapshot {
+	system.Mutex.Lock()
+	defer system.Mutex.Unlock()
+
+	alertBuffers := snapshot.FromSparseMap(system.Values)
+	values := snapshot.Entries[[]models.MachineInfo]{
+		Keys:   alertBuffers.Keys,
+		Values: make([][]models.MachineInfo, len(alertBuffers.Values)),
+	}
+	for i := range alertBuffers.Values {
+		values.Values[i] = slices.Clone(buffers.ValuesOfSetBuffer(&alertBuffers.Values[i]))
+	}
+
+	return Snapshot{
+		Values:    values,
+		Overflow:  slices.Clone(system.Overflow),
+		Rewritten: slices.Clone(system.Rewritten),
+	}
+}
+
+// ValidateSnapshot reports what RestoreSnapshot can not restore into a
+// buffer of agentsAmount agents.
+func ValidateSnapshot(saved Snapshot, agentsAmount uint64) error {
+	errs := []error{}
+
+	errs = append(errs, snapshot.ValidateEntries("buffer values", saved.Values, agentsAmount))
+	if uint64(len(saved.Rewritten)) > agentsAmount {
+		errs = append(errs, fmt.Errorf("buffer has %d rewritten counts for %d agents", len(saved.Rewritten), agentsAmount))
+	}
+
+	return errors.Join(errs...)
+}
+
+func RestoreSnapshot(system *BufferSystem, saved Snapshot) {
+	system.Mutex.Lock()
+	defer system.Mutex.Unlock()
+
+	values := saved.Values
+	alertBuffers := snapshot.Entries[buffers.SetBuffer[models.MachineInfo, uint64]]{
+		Keys:   values.Keys,
+		Values: make([]buffers.SetBuffer[models.MachineInfo, uint64], len(values.Values)),
+	}
+	for i, alerts := range values.Values {
+		alertBuffer := &alertBuffers.Values[i]
+		alertBuffer.Array = make([]models.MachineInfo, max(system.AlertsCapacity, uint64(len(alerts))))
+		buffers.AppendToSetBuffer(alertBuffer, alerts...)
+	}
+	snapshot.IntoSparseMap(system.Values, alertBuffers)
+
+	system.Overflow = append(system.Overflow[:0], saved.Overflow...)
+	copy(system.Rewritten, saved.Rewritten)
+}
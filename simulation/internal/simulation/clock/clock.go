@@ -0,0 +1,33 @@
+package clock
+
+import (
+	"math"
+
+	"github.com/StantStantov/rps/swamp/atomic"
+)
+
+type ClockSystem struct {
+	Timestamp atomic.Uint64
+}
+
+func NewClockSystem() *ClockSystem {
+	system := &ClockSystem{}
+
+	atomic.StoreUint64(&system.Timestamp, math.Float64bits(0))
+
+	return system
+}
+
+func Now(system *ClockSystem) float64 {
+	bits := atomic.LoadUint64(&system.Timestamp)
+
+	return math.Float64frombits(bits)
+}
+
+func Advance(system *ClockSystem, delta float64) {
+	SetTo(system, Now(system)+delta)
+}
+
+func SetTo(system *ClockSystem, timestamp float64) {
+	atomic.StoreUint64(&system.Timestamp, math.Float64bits(timestamp))
+}
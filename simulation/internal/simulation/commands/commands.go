@@ -12,8 +12,17 @@ type (
 const (
 	QuitCommand CommandType = iota
 	PauseCommand
+	SnapshotCommand
+	SpeedUpCommand
+	SlowDownCommand
+	StepCommand
+	ParametersCommand
+
+	commandsAmount
 )
 
+const queueCapacity = 16
+
 type CommandsSystem struct {
 	Queue *ringbuffer.RingBuffer[CommandType, CommandType]
 	Funcs []CommandFunc
@@ -22,8 +31,8 @@ type CommandsSystem struct {
 func NewCommandsSystem() *CommandsSystem {
 	system := &CommandsSystem{}
 
-	system.Queue = ringbuffer.New[CommandType, CommandType](2)
-	system.Funcs = make([]CommandFunc, 2)
+	system.Queue = ringbuffer.New[CommandType, CommandType](queueCapacity)
+	system.Funcs = make([]CommandFunc, commandsAmount)
 
 	return system
 }
@@ -7,6 +7,8 @@ import (
 	"StantStantov/ASS/internal/simulation/pools"
 
 	"github.com/StantStantov/rps/swamp/behaivors/buffers"
+	"github.com/StantStantov/rps/swamp/bools"
+	"github.com/StantStantov/rps/swamp/filters"
 	"github.com/StantStantov/rps/swamp/logging"
 	"github.com/StantStantov/rps/swamp/logging/logfmt"
 )
@@ -38,7 +40,7 @@ func NewDispatchSystem(
 	return system
 }
 
-func SaveAlerts(system *DispatchSystem, ids []models.AgentId, alertsBatches [][]models.MachineInfo) {
+func SaveAlerts(system *DispatchSystem, ids []models.AgentId, alertsBatches [][]models.MachineInfo) []bool {
 	logging.GetThenSendDebug(
 		system.Logger,
 		"going to save jobs",
@@ -49,8 +51,17 @@ func SaveAlerts(system *DispatchSystem, ids []models.AgentId, alertsBatches [][]
 		},
 	)
 
-	buffer.AddIntoBuffer(system.AlertsBuffer, ids, alertsBatches)
-	pools.MoveIfNewIntoPool(system.AlertsPool, ids)
+	areRejected := buffer.AddIntoBuffer(system.AlertsBuffer, ids, alertsBatches)
+
+	acceptedAmount := bools.CountFalse[uint64](areRejected...)
+	idsAccepted := make([]models.AgentId, acceptedAmount)
+	idsAcceptedBuffer := &buffers.SetBuffer[models.AgentId, uint64]{Array: idsAccepted}
+	filters.KeepIfFalse(idsAcceptedBuffer, ids, areRejected)
+	alertsAccepted := make([][]models.MachineInfo, acceptedAmount)
+	alertsAcceptedBuffer := &buffers.SetBuffer[[]models.MachineInfo, uint64]{Array: alertsAccepted}
+	filters.KeepIfFalse(alertsAcceptedBuffer, alertsBatches, areRejected)
+
+	pools.MoveIfNewIntoPool(system.AlertsPool, idsAccepted, alertsAccepted)
 
 	logging.GetThenSendInfo(
 		system.Logger,
@@ -63,10 +74,13 @@ func SaveAlerts(system *DispatchSystem, ids []models.AgentId, alertsBatches [][]
 
 			logfmt.Unsigneds(event, "jobs.ids", ids...)
 			logfmt.Integers(event, "jobs.alerts.amount", amounts...)
+			logfmt.Unsigned(event, "jobs.rejected_amount", bools.CountTrue[uint64](areRejected...))
 
 			return nil
 		},
 	)
+
+	return areRejected
 }
 
 func GetFreeJobs(system *DispatchSystem, setBuffer *buffers.SetBuffer[models.Job, uint64]) {
@@ -85,7 +99,7 @@ func GetFreeJobs(system *DispatchSystem, setBuffer *buffers.SetBuffer[models.Job
 	alertsBatches := make([][]models.MachineInfo, cap(setBuffer.Array))
 	alertsBuffer := &buffers.SetBuffer[[]models.MachineInfo, uint64]{Array: alertsBatches}
 	pools.GetFromPool(system.AlertsPool, idsBuffer)
-	buffer.GetMultipleFromBuffer(system.AlertsBuffer, alertsBuffer, ids...)
+	buffer.GetMultipleFromBuffer(system.AlertsBuffer, alertsBuffer, ids[:idsBuffer.Length]...)
 
 	minLength := min(idsBuffer.Length, alertsBuffer.Length)
 	for i := range minLength {
@@ -141,3 +155,24 @@ func PutBusyJobs(system *DispatchSystem, jobs ...models.Job) {
 		},
 	)
 }
+
+// ExpireJobs drops the jobs that waited in the pool past its timeout
+// together with their alerts.
+func ExpireJobs(system *DispatchSystem, ids ...uint64) {
+	idsExpired := pools.ExpireFromPool(system.AlertsPool, ids...)
+	if len(idsExpired) == 0 {
+		return
+	}
+
+	buffer.ResetAlertsInBuffer(system.AlertsBuffer, idsExpired...)
+
+	logging.GetThenSendInfo(
+		system.Logger,
+		"expired jobs",
+		func(event *logging.Event, level logging.Level) error {
+			logfmt.Unsigneds(event, "jobs.ids", idsExpired...)
+
+			return nil
+		},
+	)
+}
@@ -0,0 +1,121 @@
+package events
+
+import (
+	"container/heap"
+	"math"
+	"slices"
+)
+
+type Engine uint8
+
+const (
+	TickEngine Engine = iota
+	EventEngine
+)
+
+var EnginesNames = []string{
+	"tick",
+	"event",
+}
+
+func EngineFromName(name string) (Engine, bool) {
+	index := slices.Index(EnginesNames, name)
+	if index < 0 {
+		return TickEngine, false
+	}
+
+	return Engine(index), true
+}
+
+type EventType uint8
+
+const (
+	TickEvent EventType = iota
+	ArrivalEvent
+	CompletionEvent
+	TimeoutEvent
+)
+
+type Event struct {
+	Timestamp float64
+	Sequence  uint64
+	Type      EventType
+	Id        uint64
+}
+
+type EventsSystem struct {
+	Queue    eventsHeap
+	Sequence uint64
+}
+
+func NewEventsSystem() *EventsSystem {
+	system := &EventsSystem{}
+
+	system.Queue = eventsHeap{}
+	system.Sequence = 0
+
+	return system
+}
+
+func Schedule(system *EventsSystem, timestamp float64, eventType EventType, id uint64) bool {
+	if math.IsInf(timestamp, 1) || math.IsNaN(timestamp) {
+		return false
+	}
+
+	event := Event{
+		Timestamp: timestamp,
+		Sequence:  system.Sequence,
+		Type:      eventType,
+		Id:        id,
+	}
+	system.Sequence++
+
+	heap.Push(&system.Queue, event)
+
+	return true
+}
+
+func Pop(system *EventsSystem) (Event, bool) {
+	if len(system.Queue) == 0 {
+		return Event{}, false
+	}
+
+	event := heap.Pop(&system.Queue).(Event)
+
+	return event, true
+}
+
+func Length(system *EventsSystem) uint64 {
+	return uint64(len(system.Queue))
+}
+
+type eventsHeap []Event
+
+func (h eventsHeap) Len() int {
+	return len(h)
+}
+
+func (h eventsHeap) Less(i, j int) bool {
+	if h[i].Timestamp != h[j].Timestamp {
+		return h[i].Timestamp < h[j].Timestamp
+	}
+
+	return h[i].Sequence < h[j].Sequence
+}
+
+func (h eventsHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+}
+
+func (h *eventsHeap) Push(value any) {
+	*h = append(*h, value.(Event))
+}
+
+func (h *eventsHeap) Pop() any {
+	old := *h
+	last := len(old) - 1
+	event := old[last]
+	*h = old[:last]
+
+	return event
+}
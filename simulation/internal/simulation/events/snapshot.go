@@ -0,0 +1,22 @@
+package events
+
+import "slices"
+
+// The queue is saved as its heap array, so a restored queue pops events in
+// the same order.
+type Snapshot struct {
+	Queue    []Event `json:"queue"`
+	Sequence uint64  `json:"sequence"`
+}
+
+func TakeSnapshot(system *EventsSystem) Snapshot {
+	return Snapshot{
+		Queue:    slices.Clone(system.Queue),
+		Sequence: system.Sequence,
+	}
+}
+
+func RestoreSnapshot(system *EventsSystem, saved Snapshot) {
+	system.Queue = slices.Clone(eventsHeap(saved.Queue))
+	system.Sequence = saved.Sequence
+}
@@ -0,0 +1,209 @@
+package jobs
+
+import (
+	"StantStantov/ASS/internal/simulation/clock"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/StantStantov/rps/swamp/logging"
+	"github.com/StantStantov/rps/swamp/logging/logfmt"
+)
+
+type JobState uint8
+
+const (
+	NewState JobState = iota
+	QueuedState
+	DispatchedState
+	InProgressState
+	ResolvedState
+	ExpiredState
+)
+
+// Only the latest transitions of each job are kept.
+const HistoryCapacity = 32
+
+var JobStatesNames = []string{
+	"new",
+	"queued",
+	"dispatched",
+	"in_progress",
+	"resolved",
+	"expired",
+}
+
+var JobStatesTransitions = [][]JobState{
+	NewState:        {QueuedState},
+	QueuedState:     {DispatchedState, ExpiredState},
+	DispatchedState: {InProgressState},
+	InProgressState: {ResolvedState},
+	ResolvedState:   nil,
+	ExpiredState:    nil,
+}
+
+func JobStateFromName(name string) (JobState, bool) {
+	index := slices.Index(JobStatesNames, name)
+	if index < 0 {
+		return NewState, false
+	}
+
+	return JobState(index), true
+}
+
+func IsTerminal(state JobState) bool {
+	return state == ResolvedState || state == ExpiredState
+}
+
+func CanTransit(from JobState, to JobState) bool {
+	return slices.Contains(JobStatesTransitions[from], to)
+}
+
+type Transition struct {
+	From JobState `json:"from"`
+	To   JobState `json:"to"`
+	At   float64  `json:"at"`
+}
+
+type JobsSystem struct {
+	States    []JobState
+	Created   []bool
+	Histories [][]Transition
+	Counts    []uint64
+
+	Mutex *sync.Mutex
+
+	Clock  *clock.ClockSystem
+	Logger *logging.Logger
+}
+
+func NewJobsSystem(
+	capacity uint64,
+	clock *clock.ClockSystem,
+	logger *logging.Logger,
+) *JobsSystem {
+	system := &JobsSystem{}
+
+	system.States = make([]JobState, capacity)
+	system.Created = make([]bool, capacity)
+	system.Histories = make([][]Transition, capacity)
+	system.Counts = make([]uint64, len(JobStatesNames))
+
+	system.Mutex = &sync.Mutex{}
+
+	system.Clock = clock
+	system.Logger = logging.NewChildLogger(logger, func(event *logging.Event) {
+		logfmt.String(event, "from", "jobs_system")
+	})
+
+	return system
+}
+
+// Create starts a new lifecycle for each job that has none yet or whose
+// previous one has ended.
+func Create(system *JobsSystem, ids ...uint64) {
+	system.Mutex.Lock()
+	defer system.Mutex.Unlock()
+
+	now := clock.Now(system.Clock)
+	for _, id := range ids {
+		if system.Created[id] && !IsTerminal(system.States[id]) {
+			panic(fmt.Sprintf("Create Job %v in state %v", id, JobStatesNames[system.States[id]]))
+		}
+
+		from := system.States[id]
+		if !system.Created[id] {
+			from = NewState
+		}
+		system.Created[id] = true
+		system.States[id] = NewState
+		system.Counts[NewState]++
+		appendTransition(system, id, Transition{From: from, To: NewState, At: now})
+	}
+
+	logging.GetThenSendDebug(
+		system.Logger,
+		"created jobs",
+		func(event *logging.Event, level logging.Level) error {
+			logfmt.Unsigneds(event, "jobs.ids", ids...)
+
+			return nil
+		},
+	)
+}
+
+// Transit moves jobs into the state and panics on a transition the state
+// machine does not allow.
+func Transit(system *JobsSystem, to JobState, ids ...uint64) {
+	system.Mutex.Lock()
+	defer system.Mutex.Unlock()
+
+	now := clock.Now(system.Clock)
+	for _, id := range ids {
+		from := system.States[id]
+		if !system.Created[id] || !CanTransit(from, to) {
+			panic(fmt.Sprintf("Transit Job %v from %v to %v", id, JobStatesNames[from], JobStatesNames[to]))
+		}
+
+		system.States[id] = to
+		system.Counts[from]--
+		system.Counts[to]++
+		appendTransition(system, id, Transition{From: from, To: to, At: now})
+	}
+
+	logging.GetThenSendDebug(
+		system.Logger,
+		"moved jobs into new state",
+		func(event *logging.Event, level logging.Level) error {
+			logfmt.Unsigneds(event, "jobs.ids", ids...)
+			logfmt.String(event, "jobs.state", JobStatesNames[to])
+
+			return nil
+		},
+	)
+}
+
+func StateOf(system *JobsSystem, id uint64) (JobState, bool) {
+	system.Mutex.Lock()
+	defer system.Mutex.Unlock()
+
+	return system.States[id], system.Created[id]
+}
+
+// History returns the latest transitions of the job, oldest first.
+func History(system *JobsSystem, id uint64) []Transition {
+	system.Mutex.Lock()
+	defer system.Mutex.Unlock()
+
+	return slices.Clone(system.Histories[id])
+}
+
+// Counts of the active states hold jobs in them right now, counts of the
+// terminal states hold jobs that have ended in them.
+func CountsPerState(system *JobsSystem) []uint64 {
+	system.Mutex.Lock()
+	defer system.Mutex.Unlock()
+
+	return slices.Clone(system.Counts)
+}
+
+// Terminal counts are statistics, active counts describe jobs still in the
+// system and stay.
+func ResetStatistics(system *JobsSystem) {
+	system.Mutex.Lock()
+	defer system.Mutex.Unlock()
+
+	for state := range system.Counts {
+		if IsTerminal(JobState(state)) {
+			system.Counts[state] = 0
+		}
+	}
+}
+
+func appendTransition(system *JobsSystem, id uint64, transition Transition) {
+	history := system.Histories[id]
+	if len(history) == HistoryCapacity {
+		history = slices.Delete(history, 0, 1)
+	}
+	system.Histories[id] = append(history, transition)
+}
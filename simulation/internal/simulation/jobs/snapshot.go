@@ -0,0 +1,52 @@
+package jobs
+
+import (
+	"fmt"
+	"slices"
+)
+
+type Snapshot struct {
+	States    []JobState     `json:"states"`
+	Created   []bool         `json:"created"`
+	Histories [][]Transition `json:"histories"`
+	Counts    []uint64       `json:"counts"`
+}
+
+func TakeSnapshot(system *JobsSystem) Snapshot {
+	system.Mutex.Lock()
+	defer system.Mutex.Unlock()
+
+	histories := make([][]Transition, len(system.Histories))
+	for i, history := range system.Histories {
+		histories[i] = slices.Clone(history)
+	}
+
+	return Snapshot{
+		States:    slices.Clone(system.States),
+		Created:   slices.Clone(system.Created),
+		Histories: histories,
+		Counts:    slices.Clone(system.Counts),
+	}
+}
+
+// ValidateSnapshot reports what RestoreSnapshot can not restore into a
+// system of capacity jobs.
+func ValidateSnapshot(saved Snapshot, capacity uint64) error {
+	if uint64(len(saved.Histories)) > capacity {
+		return fmt.Errorf("jobs have %d histories for %d jobs", len(saved.Histories), capacity)
+	}
+
+	return nil
+}
+
+func RestoreSnapshot(system *JobsSystem, saved Snapshot) {
+	system.Mutex.Lock()
+	defer system.Mutex.Unlock()
+
+	copy(system.States, saved.States)
+	copy(system.Created, saved.Created)
+	for i, history := range saved.Histories {
+		system.Histories[i] = slices.Clone(history)
+	}
+	copy(system.Counts, saved.Counts)
+}
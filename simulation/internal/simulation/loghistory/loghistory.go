@@ -0,0 +1,155 @@
+package loghistory
+
+import (
+	"bytes"
+	"slices"
+	"sort"
+	"sync"
+)
+
+type Unit uint8
+
+const (
+	LinesUnit Unit = iota
+	TicksUnit
+)
+
+var UnitsNames = []string{
+	"lines",
+	"ticks",
+}
+
+func UnitFromName(name string) (Unit, bool) {
+	index := slices.Index(UnitsNames, name)
+	if index < 0 {
+		return LinesUnit, false
+	}
+
+	return Unit(index), true
+}
+
+type Entry struct {
+	Tick uint64
+	Line string
+}
+
+// History keeps the last Size lines or the lines of the last Size ticks.
+// Every line gets an index that stays the same while the line is kept.
+type History struct {
+	Entries []Entry
+	Start   int
+	First   uint64
+	Tick    uint64
+	Size    uint64
+	Unit    Unit
+
+	Pending []byte
+	Mutex   *sync.Mutex
+}
+
+func NewHistory(size uint64, unit Unit) *History {
+	history := &History{}
+
+	history.Entries = []Entry{}
+	history.Start = 0
+	history.First = 0
+	history.Tick = 0
+	history.Size = size
+	history.Unit = unit
+
+	history.Pending = []byte{}
+	history.Mutex = &sync.Mutex{}
+
+	return history
+}
+
+// Write splits p into lines, a line without its newline waits for the
+// next write.
+func (h *History) Write(p []byte) (n int, err error) {
+	h.Mutex.Lock()
+	defer h.Mutex.Unlock()
+
+	h.Pending = append(h.Pending, p...)
+	for {
+		end := bytes.IndexByte(h.Pending, '\n')
+		if end < 0 {
+			break
+		}
+
+		h.Entries = append(h.Entries, Entry{Tick: h.Tick, Line: string(h.Pending[:end])})
+		h.Pending = h.Pending[end+1:]
+	}
+	h.Pending = slices.Clip(h.Pending)
+	trim(h)
+
+	return len(p), nil
+}
+
+func SetTick(history *History, tick uint64) {
+	history.Mutex.Lock()
+	defer history.Mutex.Unlock()
+
+	history.Tick = tick
+	trim(history)
+}
+
+// Bounds return the index of the first kept line and the index the next
+// line will get.
+func Bounds(history *History) (uint64, uint64) {
+	history.Mutex.Lock()
+	defer history.Mutex.Unlock()
+
+	return history.First, history.First + uint64(len(history.Entries)-history.Start)
+}
+
+// Lines copy the kept lines with indices in [from, to) and return the
+// index of the first copied line.
+func Lines(history *History, from, to uint64) (uint64, []Entry) {
+	history.Mutex.Lock()
+	defer history.Mutex.Unlock()
+
+	end := history.First + uint64(len(history.Entries)-history.Start)
+	from = min(max(from, history.First), end)
+	to = min(max(to, from), end)
+	offset := uint64(history.Start) - history.First
+
+	return from, slices.Clone(history.Entries[from+offset : to+offset])
+}
+
+// LineOfTick returns the index of the first kept line written at tick or
+// later.
+func LineOfTick(history *History, tick uint64) uint64 {
+	history.Mutex.Lock()
+	defer history.Mutex.Unlock()
+
+	kept := history.Entries[history.Start:]
+	index := sort.Search(len(kept), func(i int) bool {
+		return kept[i].Tick >= tick
+	})
+
+	return history.First + uint64(index)
+}
+
+func trim(history *History) {
+	dropped := 0
+	kept := history.Entries[history.Start:]
+	switch history.Unit {
+	case LinesUnit:
+		dropped = max(len(kept)-int(history.Size), 0)
+	case TicksUnit:
+		dropped = sort.Search(len(kept), func(i int) bool {
+			return kept[i].Tick+history.Size > history.Tick
+		})
+	}
+
+	clear(kept[:dropped])
+	history.Start += dropped
+	history.First += uint64(dropped)
+
+	if history.Start > len(history.Entries)/2 {
+		length := copy(history.Entries, history.Entries[history.Start:])
+		clear(history.Entries[length:])
+		history.Entries = history.Entries[:length]
+		history.Start = 0
+	}
+}
@@ -1,6 +1,11 @@
 package metrics
 
 import (
+	"StantStantov/ASS/internal/simulation/clock"
+	"math"
+	"slices"
+	"sync"
+
 	"github.com/StantStantov/rps/swamp/atomic"
 
 	"github.com/StantStantov/rps/swamp/logging"
@@ -15,14 +20,17 @@ const (
 
 	AlertsBufferedCounter
 	AlertsRewrittenCounter
+	AlertsSpilledCounter
+	AlertsDroppedCounter
+	AlertsOverwrittenCounter
+	AlertsRejectedCounter
+	AlertsSpillDroppedCounter
 
 	JobsPendingCounter
 	JobsSkippedCounter
 	JobsLockedCounter
 	JobsUnlockedCounter
-
-	RespondersFreeCounter
-	RespondersBusyCounter
+	JobsExpiredCounter
 )
 
 var MetricTypesNames = []string{
@@ -31,28 +39,135 @@ var MetricTypesNames = []string{
 
 	"alerts_added_to_buffer_total",
 	"alerts_rewritten_in_buffer_total",
+	"alerts_spilled_to_overflow_total",
+	"alerts_refused_drop_newest_total",
+	"alerts_refused_overwrite_oldest_total",
+	"alerts_refused_reject_batch_total",
+	"alerts_refused_spill_total",
 
 	"jobs_added_to_pool_total",
 	"jobs_skipped_pool_total",
 	"jobs_started_total",
 	"jobs_finished_total",
+	"jobs_expired_total",
+}
+
+var MetricTypesDescriptions = []string{
+	"Agents that stayed silent when polled.",
+	"Agents that raised alerts when polled.",
+
+	"Alerts added into agents' buffers.",
+	"Alerts refused by full agents' buffers.",
+	"Alerts moved into the shared overflow area.",
+	"Alerts dropped by the drop_newest refusal policy.",
+	"Alerts overwritten by the overwrite_oldest refusal policy.",
+	"Alerts rejected by the reject_batch refusal policy.",
+	"Alerts dropped by the spill refusal policy with a full overflow area.",
+
+	"Jobs added into the pool.",
+	"Alerts merged into jobs already in the pool.",
+	"Jobs given to responders.",
+	"Jobs finished by responders.",
+	"Jobs that waited in the pool past the job timeout.",
+}
+
+type GaugeType uint8
+
+const (
+	JobsWaitingGauge GaugeType = iota
+	JobsInPoolGauge
+	JobsLockedGauge
+	RespondersFreeGauge
+	RespondersBusyGauge
+)
+
+var GaugeTypesNames = []string{
+	"jobs_waiting",
+	"jobs_in_pool",
+	"jobs_locked",
+	"responders_free",
+	"responders_busy",
+}
+
+var GaugeTypesDescriptions = []string{
+	"Jobs in the pool waiting for a responder.",
+	"Jobs in the pool, waiting or handled.",
+	"Jobs in the pool handled by responders.",
+	"Responders without a job.",
+	"Responders handling a job.",
+}
 
-	"responders_free_total",
-	"responders_busy_total",
+type HistogramType uint8
+
+const (
+	TimeInPoolHistogram HistogramType = iota
+	ServiceTimeHistogram
+	BatchSizeHistogram
+)
+
+var HistogramTypesNames = []string{
+	"time_in_pool_seconds",
+	"service_time_seconds",
+	"alerts_batch_size",
 }
 
+var HistogramTypesDescriptions = []string{
+	"Simulated seconds jobs spent in the pool.",
+	"Simulated seconds responders spent handling a job.",
+	"Alerts in a batch sent by an agent.",
+}
+
+var HistogramTypesBuckets = [][]float64{
+	{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 25, 50, 100},
+	{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 25, 50, 100},
+	{1, 2, 4, 8, 16, 32, 64, 128},
+}
+
+type MetricKind uint8
+
+const (
+	CounterMetric MetricKind = iota
+	GaugeMetric
+	HistogramMetric
+)
+
 type MetricsSystem struct {
-	Metrics []atomic.Uint64
+	Metrics    []atomic.Uint64
+	Gauges     []atomic.Uint64
+	Histograms []Histogram
+	StartedAt  float64
 
+	Clock  *clock.ClockSystem
 	Logger *logging.Logger
 }
 
+type Histogram struct {
+	Bounds []float64
+	Counts []uint64
+	Count  uint64
+	Sum    float64
+
+	Mutex *sync.Mutex
+}
+
 type Metric struct {
-	Name  string
-	Value uint64
+	Name        string
+	Description string
+	Kind        MetricKind
+	Value       uint64
+
+	Buckets []Bucket
+	Sum     float64
+}
+
+// Bucket counts are cumulative, the last bucket has an infinite bound.
+type Bucket struct {
+	UpperBound float64
+	Count      uint64
 }
 
 func NewMetricsSystem(
+	clockSystem *clock.ClockSystem,
 	logger *logging.Logger,
 ) *MetricsSystem {
 	system := &MetricsSystem{}
@@ -62,32 +177,148 @@ func NewMetricsSystem(
 		atomicValue := &system.Metrics[i]
 		atomic.StoreUint64(atomicValue, 0)
 	}
+	system.Gauges = make([]atomic.Uint64, len(GaugeTypesNames))
+	for i := range system.Gauges {
+		atomicValue := &system.Gauges[i]
+		atomic.StoreUint64(atomicValue, 0)
+	}
+	system.Histograms = make([]Histogram, len(HistogramTypesNames))
+	for i := range system.Histograms {
+		system.Histograms[i] = Histogram{
+			Bounds: HistogramTypesBuckets[i],
+			Counts: make([]uint64, len(HistogramTypesBuckets[i])+1),
+			Mutex:  &sync.Mutex{},
+		}
+	}
+	system.StartedAt = clock.Now(clockSystem)
+
+	system.Clock = clockSystem
 
 	system.Logger = logging.NewChildLogger(logger, func(event *logging.Event) {
-		logfmt.String(event, "from", "dispatch_system")
+		logfmt.String(event, "from", "metrics_system")
 	})
 
 	return system
 }
 
+func MetricsAmount(system *MetricsSystem) int {
+	return len(system.Metrics) + len(system.Gauges) + len(system.Histograms)
+}
+
+// Indexes into GetMetrics, which lists counters, then gauges, then histograms.
+func GaugeIndex(system *MetricsSystem, gauge GaugeType) int {
+	return len(system.Metrics) + int(gauge)
+}
+
+func HistogramIndex(system *MetricsSystem, histogram HistogramType) int {
+	return len(system.Metrics) + len(system.Gauges) + int(histogram)
+}
+
 func GetMetrics(system *MetricsSystem, setMetricBuffer []Metric) []Metric {
-	minLength := min(len(setMetricBuffer), len(MetricTypesNames), len(system.Metrics))
+	minLength := min(len(setMetricBuffer), MetricsAmount(system))
 	for i := range minLength {
-		name := MetricTypesNames[i]
-		atomicValue := &system.Metrics[i]
-		value := atomic.LoadUint64(atomicValue)
+		setMetricBuffer[i] = getMetric(system, i)
+	}
+
+	return setMetricBuffer[:minLength]
+}
 
-		metric := Metric{
-			Name:  name,
-			Value: value,
+func getMetric(system *MetricsSystem, index int) Metric {
+	if index < len(system.Metrics) {
+		atomicValue := &system.Metrics[index]
+
+		return Metric{
+			Name:        MetricTypesNames[index],
+			Description: MetricTypesDescriptions[index],
+			Kind:        CounterMetric,
+			Value:       atomic.LoadUint64(atomicValue),
 		}
-		setMetricBuffer[i] = metric
 	}
 
-	return setMetricBuffer[:minLength]
+	index -= len(system.Metrics)
+	if index < len(system.Gauges) {
+		atomicValue := &system.Gauges[index]
+
+		return Metric{
+			Name:        GaugeTypesNames[index],
+			Description: GaugeTypesDescriptions[index],
+			Kind:        GaugeMetric,
+			Value:       atomic.LoadUint64(atomicValue),
+		}
+	}
+
+	index -= len(system.Gauges)
+	histogram := &system.Histograms[index]
+	histogram.Mutex.Lock()
+	defer histogram.Mutex.Unlock()
+
+	buckets := make([]Bucket, len(histogram.Counts))
+	cumulative := uint64(0)
+	for i, count := range histogram.Counts {
+		cumulative += count
+		upperBound := math.Inf(1)
+		if i < len(histogram.Bounds) {
+			upperBound = histogram.Bounds[i]
+		}
+		buckets[i] = Bucket{UpperBound: upperBound, Count: cumulative}
+	}
+
+	return Metric{
+		Name:        HistogramTypesNames[index],
+		Description: HistogramTypesDescriptions[index],
+		Kind:        HistogramMetric,
+		Value:       histogram.Count,
+		Buckets:     buckets,
+		Sum:         histogram.Sum,
+	}
 }
 
 func AddToMetric(system *MetricsSystem, metric MetricType, value uint64) {
 	atomicValue := &system.Metrics[metric]
 	atomic.AddUint64(atomicValue, value)
 }
+
+func SetGauge(system *MetricsSystem, gauge GaugeType, value uint64) {
+	atomicValue := &system.Gauges[gauge]
+	atomic.StoreUint64(atomicValue, value)
+}
+
+func LoadGauge(system *MetricsSystem, gauge GaugeType) uint64 {
+	atomicValue := &system.Gauges[gauge]
+
+	return atomic.LoadUint64(atomicValue)
+}
+
+func ObserveHistogram(system *MetricsSystem, histogramType HistogramType, values ...float64) {
+	histogram := &system.Histograms[histogramType]
+	histogram.Mutex.Lock()
+	defer histogram.Mutex.Unlock()
+
+	for _, value := range values {
+		bucket, _ := slices.BinarySearch(histogram.Bounds, value)
+		histogram.Counts[bucket]++
+		histogram.Count++
+		histogram.Sum += value
+	}
+}
+
+// Gauges describe the current state, so only counters and histograms are reset.
+func ResetMetrics(system *MetricsSystem) {
+	for i := range system.Metrics {
+		atomicValue := &system.Metrics[i]
+		atomic.StoreUint64(atomicValue, 0)
+	}
+	for i := range system.Histograms {
+		histogram := &system.Histograms[i]
+		histogram.Mutex.Lock()
+		clear(histogram.Counts)
+		histogram.Count = 0
+		histogram.Sum = 0
+		histogram.Mutex.Unlock()
+	}
+	system.StartedAt = clock.Now(system.Clock)
+}
+
+func ElapsedSeconds(system *MetricsSystem) float64 {
+	return clock.Now(system.Clock) - system.StartedAt
+}
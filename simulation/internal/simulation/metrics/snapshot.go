@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/StantStantov/rps/swamp/atomic"
+)
+
+type HistogramSnapshot struct {
+	Counts []uint64 `json:"counts"`
+	Count  uint64   `json:"count"`
+	Sum    float64  `json:"sum"`
+}
+
+type Snapshot struct {
+	Counters   []uint64            `json:"counters"`
+	Gauges     []uint64            `json:"gauges"`
+	Histograms []HistogramSnapshot `json:"histograms"`
+	StartedAt  float64             `json:"started_at"`
+}
+
+func TakeSnapshot(system *MetricsSystem) Snapshot {
+	saved := Snapshot{}
+
+	saved.Counters = make([]uint64, len(system.Metrics))
+	for i := range system.Metrics {
+		atomicValue := &system.Metrics[i]
+		saved.Counters[i] = atomic.LoadUint64(atomicValue)
+	}
+	saved.Gauges = make([]uint64, len(system.Gauges))
+	for i := range system.Gauges {
+		atomicValue := &system.Gauges[i]
+		saved.Gauges[i] = atomic.LoadUint64(atomicValue)
+	}
+	saved.Histograms = make([]HistogramSnapshot, len(system.Histograms))
+	for i := range system.Histograms {
+		histogram := &system.Histograms[i]
+		histogram.Mutex.Lock()
+		saved.Histograms[i] = HistogramSnapshot{
+			Counts: slices.Clone(histogram.Counts),
+			Count:  histogram.Count,
+			Sum:    histogram.Sum,
+		}
+		histogram.Mutex.Unlock()
+	}
+	saved.StartedAt = system.StartedAt
+
+	return saved
+}
+
+// ValidateSnapshot reports what RestoreSnapshot can not restore, a snapshot
+// can not have more metrics than the simulation defines.
+func ValidateSnapshot(saved Snapshot) error {
+	errs := []error{}
+
+	if len(saved.Counters) > len(MetricTypesNames) {
+		errs = append(errs, fmt.Errorf("metrics have %d counters, expected %d", len(saved.Counters), len(MetricTypesNames)))
+	}
+	if len(saved.Gauges) > len(GaugeTypesNames) {
+		errs = append(errs, fmt.Errorf("metrics have %d gauges, expected %d", len(saved.Gauges), len(GaugeTypesNames)))
+	}
+	if len(saved.Histograms) > len(HistogramTypesNames) {
+		errs = append(errs, fmt.Errorf("metrics have %d histograms, expected %d", len(saved.Histograms), len(HistogramTypesNames)))
+	}
+
+	return errors.Join(errs...)
+}
+
+func RestoreSnapshot(system *MetricsSystem, saved Snapshot) {
+	for i, value := range saved.Counters {
+		atomicValue := &system.Metrics[i]
+		atomic.StoreUint64(atomicValue, value)
+	}
+	for i, value := range saved.Gauges {
+		atomicValue := &system.Gauges[i]
+		atomic.StoreUint64(atomicValue, value)
+	}
+	for i, savedHistogram := range saved.Histograms {
+		histogram := &system.Histograms[i]
+		histogram.Mutex.Lock()
+		copy(histogram.Counts, savedHistogram.Counts)
+		histogram.Count = savedHistogram.Count
+		histogram.Sum = savedHistogram.Sum
+		histogram.Mutex.Unlock()
+	}
+	system.StartedAt = saved.StartedAt
+}
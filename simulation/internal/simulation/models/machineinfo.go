@@ -2,13 +2,17 @@ package models
 
 type AgentId = uint64
 
+const MaxSeverity = 5
+
 type Job struct {
 	Id     uint64
 	Alerts []MachineInfo
 }
 
 type MachineInfo struct {
-	Id uint64
+	Id        uint64
+	Severity  uint8
+	CreatedAt float64
 }
 
 func JobsToIds(jobs []Job, setBuffer []uint64) []uint64 {
@@ -2,5 +2,7 @@ package models
 
 type (
 	ResponderId   = uint64
-	ResponderInfo struct{}
+	ResponderInfo struct {
+		Priority uint64
+	}
 )
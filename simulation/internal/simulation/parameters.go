@@ -0,0 +1,145 @@
+package simulation
+
+import (
+	"StantStantov/ASS/internal/config"
+	"StantStantov/ASS/internal/simulation/buffer"
+	"StantStantov/ASS/internal/simulation/responders"
+	"sync"
+
+	"github.com/StantStantov/rps/swamp/logging"
+	"github.com/StantStantov/rps/swamp/logging/logfmt"
+)
+
+// Parameters are the part of the config that can be changed while the
+// simulation runs.
+type Parameters struct {
+	MinChanceToCrash  float32
+	MinChanceToHandle float32
+	AlertsCapacity    uint64
+	RespondersAmount  uint64
+}
+
+// parametersConfig mirrors Config for the UI goroutine, which must not read
+// Config while ApplyParameters writes it.
+var (
+	pendingParameters    = Parameters{}
+	hasPendingParameters = false
+	parametersConfig     = config.Config{}
+	parametersMutex      = &sync.Mutex{}
+)
+
+// CurrentParameters are safe to read from the UI goroutine, they are the
+// last applied ones.
+func CurrentParameters() Parameters {
+	parametersMutex.Lock()
+	defer parametersMutex.Unlock()
+
+	return Parameters{
+		MinChanceToCrash:  parametersConfig.MinChanceToCrash,
+		MinChanceToHandle: parametersConfig.MinChanceToHandle,
+		AlertsCapacity:    parametersConfig.AlertsCapacity,
+		RespondersAmount:  parametersConfig.RespondersAmount,
+	}
+}
+
+func liveParameters() Parameters {
+	return Parameters{
+		MinChanceToCrash:  AgentsSystem.MinChanceToCrash,
+		MinChanceToHandle: RespondersSystem.MinChanceToHandle,
+		AlertsCapacity:    Buffer.AlertsCapacity,
+		RespondersAmount:  uint64(len(RespondersSystem.Responders)),
+	}
+}
+
+// RequestParameters validates the parameters and keeps them until
+// ApplyParameters runs between ticks.
+func RequestParameters(parameters Parameters) error {
+	parametersMutex.Lock()
+	defer parametersMutex.Unlock()
+
+	cfg := withParameters(parametersConfig, parameters)
+	if err := config.Validate(&cfg); err != nil {
+		return err
+	}
+
+	pendingParameters = parameters
+	hasPendingParameters = true
+
+	return nil
+}
+
+func ApplyParameters() {
+	parametersMutex.Lock()
+	parameters := pendingParameters
+	hasParameters := hasPendingParameters
+	hasPendingParameters = false
+	cfg := withParameters(parametersConfig, parameters)
+	if hasParameters {
+		parametersConfig = cfg
+	}
+	parametersMutex.Unlock()
+
+	if !hasParameters {
+		return
+	}
+
+	current := liveParameters()
+	if parameters.MinChanceToCrash != current.MinChanceToCrash {
+		AgentsSystem.MinChanceToCrash = parameters.MinChanceToCrash
+		logParameterChange("min_chance_to_crash", float64(current.MinChanceToCrash), float64(parameters.MinChanceToCrash))
+	}
+	if parameters.MinChanceToHandle != current.MinChanceToHandle {
+		RespondersSystem.MinChanceToHandle = parameters.MinChanceToHandle
+		logParameterChange("min_chance_to_handle", float64(current.MinChanceToHandle), float64(parameters.MinChanceToHandle))
+	}
+	if parameters.AlertsCapacity != current.AlertsCapacity {
+		buffer.SetAlertsCapacity(Buffer, parameters.AlertsCapacity)
+		logParameterChange("alerts_capacity", float64(current.AlertsCapacity), float64(parameters.AlertsCapacity))
+	}
+	if parameters.RespondersAmount != current.RespondersAmount {
+		responders.Resize(RespondersSystem, parameters.RespondersAmount, config.RespondersPriorities(&cfg))
+		logParameterChange("responders_amount", float64(current.RespondersAmount), float64(parameters.RespondersAmount))
+	}
+
+	*Config = cfg
+}
+
+func resetParameters(cfg *config.Config) {
+	parametersMutex.Lock()
+	defer parametersMutex.Unlock()
+
+	pendingParameters = Parameters{}
+	hasPendingParameters = false
+	parametersConfig = *cfg
+}
+
+// Explicit priorities are cut or padded with the lowest priority so they
+// keep one priority per responder.
+func withParameters(cfg config.Config, parameters Parameters) config.Config {
+	cfg.MinChanceToCrash = parameters.MinChanceToCrash
+	cfg.MinChanceToHandle = parameters.MinChanceToHandle
+	cfg.AlertsCapacity = parameters.AlertsCapacity
+	cfg.RespondersAmount = parameters.RespondersAmount
+	if len(cfg.RespondersPriorities) != 0 {
+		priorities := make([]uint64, cfg.RespondersAmount)
+		copy(priorities, cfg.RespondersPriorities)
+		cfg.RespondersPriorities = priorities
+	}
+
+	return cfg
+}
+
+func logParameterChange(name string, from, to float64) {
+	logging.GetThenSendInfo(
+		Logger,
+		"changed parameter",
+		func(event *logging.Event, level logging.Level) error {
+			logfmt.String(event, "parameter.name", name)
+			logfmt.Floats64(event, "parameter.from", from)
+			logfmt.Floats64(event, "parameter.to", to)
+			logfmt.Unsigned(event, "simulation.tick", TickCounter)
+
+			return nil
+		},
+	)
+}
@@ -0,0 +1,84 @@
+package pools
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/StantStantov/rps/swamp/bools"
+	"github.com/StantStantov/rps/swamp/collections/sparsemap"
+)
+
+type Discipline uint8
+
+const (
+	FifoDiscipline Discipline = iota
+	LifoDiscipline
+	SeverityDiscipline
+	OldestAlertDiscipline
+	RandomDiscipline
+)
+
+var DisciplinesNames = []string{
+	"fifo",
+	"lifo",
+	"severity",
+	"oldest_alert",
+	"random",
+}
+
+func DisciplineFromName(name string) (Discipline, bool) {
+	index := slices.Index(DisciplinesNames, name)
+	if index < 0 {
+		return FifoDiscipline, false
+	}
+
+	return Discipline(index), true
+}
+
+func orderByDiscipline(system *PoolSystem, ids []uint64) {
+	switch system.Discipline {
+	case LifoDiscipline:
+		slices.Reverse(ids)
+	case SeverityDiscipline:
+		severities := make([]uint8, len(ids))
+		gotSeverities := make([]bool, len(ids))
+		severities, gotSeverities = sparsemap.GetFromSparseMap(system.Severities, severities, gotSeverities, ids...)
+		if bools.AnyFalse(gotSeverities...) {
+			panic(fmt.Sprintf("Get Severities %v %v", ids, gotSeverities))
+		}
+
+		sortByKeys(ids, severities, func(a, b uint8) int {
+			return cmp.Compare(b, a)
+		})
+	case OldestAlertDiscipline:
+		timestamps := make([]float64, len(ids))
+		gotTimestamps := make([]bool, len(ids))
+		timestamps, gotTimestamps = sparsemap.GetFromSparseMap(system.TimestampsFirstAlert, timestamps, gotTimestamps, ids...)
+		if bools.AnyFalse(gotTimestamps...) {
+			panic(fmt.Sprintf("Get Timestamps First Alert %v %v", ids, gotTimestamps))
+		}
+
+		sortByKeys(ids, timestamps, cmp.Compare[float64])
+	case RandomDiscipline:
+		system.Random.Rand.Shuffle(len(ids), func(i, j int) {
+			ids[i], ids[j] = ids[j], ids[i]
+		})
+	}
+}
+
+func sortByKeys[K any](ids []uint64, keys []K, compare func(a, b K) int) {
+	indexes := make([]int, len(ids))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	slices.SortStableFunc(indexes, func(a, b int) int {
+		return compare(keys[a], keys[b])
+	})
+
+	sorted := make([]uint64, len(ids))
+	for i, index := range indexes {
+		sorted[i] = ids[index]
+	}
+	copy(ids, sorted)
+}
@@ -1,5 +1,7 @@
 package pools
 
+import "StantStantov/ASS/internal/simulation/metrics"
+
 func JobsPendingTotal(system *PoolSystem) uint64 {
 	return system.Queue.Length
 }
@@ -11,3 +13,9 @@ func JobsUnlockedTotal(system *PoolSystem) uint64 {
 func JobsLockedTotal(system *PoolSystem) uint64 {
 	return uint64(len(system.Locked.Dense))
 }
+
+func updateGauges(system *PoolSystem) {
+	metrics.SetGauge(system.Metrics, metrics.JobsWaitingGauge, JobsUnlockedTotal(system))
+	metrics.SetGauge(system.Metrics, metrics.JobsInPoolGauge, JobsPendingTotal(system))
+	metrics.SetGauge(system.Metrics, metrics.JobsLockedGauge, JobsLockedTotal(system))
+}
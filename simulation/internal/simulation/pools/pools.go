@@ -1,9 +1,12 @@
 package pools
 
 import (
-	ptime "StantStantov/ASS/internal/common/time"
+	"StantStantov/ASS/internal/common/collections"
+	"StantStantov/ASS/internal/simulation/clock"
+	"StantStantov/ASS/internal/simulation/jobs"
 	"StantStantov/ASS/internal/simulation/metrics"
 	"StantStantov/ASS/internal/simulation/models"
+	"StantStantov/ASS/internal/simulation/random"
 	"fmt"
 	"sync"
 
@@ -17,9 +20,14 @@ import (
 )
 
 type PoolSystem struct {
-	Queue   *doublyList
-	Present *sparsemap.SparseMap[uint64, *poolNode]
-	Locked  *sparseset.SparseSet[uint64]
+	Queue      *doublyList
+	Present    *sparsemap.SparseMap[uint64, *poolNode]
+	Locked     *sparseset.SparseSet[uint64]
+	Discipline Discipline
+	Timeout    float64
+
+	Severities           *sparsemap.SparseMap[uint64, uint8]
+	TimestampsFirstAlert *sparsemap.SparseMap[uint64, float64]
 
 	TimestampsAdded    *sparsemap.SparseMap[uint64, float64]
 	TimestampsLocked   *sparsemap.SparseMap[uint64, float64]
@@ -31,14 +39,27 @@ type PoolSystem struct {
 	PoppedAmount    uint64
 	SpentTimeInPool float64
 
+	LockedAmount     uint64
+	SpentTimeWaiting float64
+	WaitingArea      float64
+	AreaUpdatedAt    float64
+
 	Mutex *sync.Mutex
 
+	Jobs    *jobs.JobsSystem
+	Clock   *clock.ClockSystem
+	Random  *random.Generator
 	Metrics *metrics.MetricsSystem
 	Logger  *logging.Logger
 }
 
 func NewPoolSystem(
 	capacity uint64,
+	discipline Discipline,
+	timeout float64,
+	jobs *jobs.JobsSystem,
+	clock *clock.ClockSystem,
+	random *random.Generator,
 	metrics *metrics.MetricsSystem,
 	logger *logging.Logger,
 ) *PoolSystem {
@@ -47,6 +68,11 @@ func NewPoolSystem(
 	system.Queue = &doublyList{}
 	system.Present = sparsemap.NewSparseMap[uint64, *poolNode](capacity)
 	system.Locked = sparseset.NewSparseSet(capacity)
+	system.Discipline = discipline
+	system.Timeout = timeout
+
+	system.Severities = sparsemap.NewSparseMap[uint64, uint8](capacity)
+	system.TimestampsFirstAlert = sparsemap.NewSparseMap[uint64, float64](capacity)
 
 	system.TimestampsAdded = sparsemap.NewSparseMap[uint64, float64](capacity)
 	system.TimestampsLocked = sparsemap.NewSparseMap[uint64, float64](capacity)
@@ -54,8 +80,13 @@ func NewPoolSystem(
 	system.TimeLocked = sparsemap.NewSparseMap[uint64, float64](capacity)
 	system.TimeUnlocked = sparsemap.NewSparseMap[uint64, float64](capacity)
 
+	system.AreaUpdatedAt = 0
+
 	system.Mutex = &sync.Mutex{}
 
+	system.Jobs = jobs
+	system.Clock = clock
+	system.Random = random
 	system.Metrics = metrics
 	system.Logger = logging.NewChildLogger(logger, func(event *logging.Event) {
 		logfmt.String(event, "from", "pool_system")
@@ -64,7 +95,7 @@ func NewPoolSystem(
 	return system
 }
 
-func MoveIfNewIntoPool(system *PoolSystem, ids []models.AgentId) {
+func MoveIfNewIntoPool(system *PoolSystem, ids []models.AgentId, alertsBatches [][]models.MachineInfo) {
 	system.Mutex.Lock()
 	defer system.Mutex.Unlock()
 
@@ -86,6 +117,7 @@ func MoveIfNewIntoPool(system *PoolSystem, ids []models.AgentId) {
 		}
 	}
 
+	updateWaitingArea(system)
 	pushNodesIntoDoublyList(system.Queue, nodesFiltered...)
 
 	movedIntoPool := make([]bool, len(nodesFiltered))
@@ -94,7 +126,7 @@ func MoveIfNewIntoPool(system *PoolSystem, ids []models.AgentId) {
 		panic(fmt.Sprintf("Add into Pool %v %v", idsFiltered, movedIntoPool))
 	}
 
-	addTime := ptime.TimeNowInSeconds()
+	addTime := clock.Now(system.Clock)
 	timestamps := make([]float64, idsNewAmount)
 	for i := range timestamps {
 		timestamps[i] = addTime
@@ -106,6 +138,11 @@ func MoveIfNewIntoPool(system *PoolSystem, ids []models.AgentId) {
 		panic(fmt.Sprintf("Added Timestamps %v %v", idsFiltered, addTimestamps))
 	}
 
+	saveAlertsOrdering(system, ids, alertsBatches, arePresent)
+	jobs.Create(system.Jobs, idsFiltered...)
+	jobs.Transit(system.Jobs, jobs.QueuedState, idsFiltered...)
+	updateGauges(system)
+
 	metrics.AddToMetric(system.Metrics, metrics.JobsPendingCounter, idsNewAmount)
 	metrics.AddToMetric(system.Metrics, metrics.JobsSkippedCounter, bools.CountTrue[uint64](arePresent...))
 
@@ -146,12 +183,16 @@ func GetFromPool(system *PoolSystem, setBuffer *buffers.SetBuffer[uint64, uint64
 		currentNode = currentNode.Next
 	}
 
+	orderByDiscipline(system, allIds)
+
 	areLocked := make([]bool, queue.Length)
 	areLocked = sparseset.PresentInSparseSet(system.Locked, areLocked, allIds...)
 
 	filters.KeepIfFalse(setBuffer, allIds, areLocked)
 	idsFiltered := buffers.ValuesOfSetBuffer(setBuffer)
 
+	updateWaitingArea(system)
+
 	jobsToLockAmount := setBuffer.Length
 	lockedJobs := make([]bool, jobsToLockAmount)
 	lockedJobs = sparseset.AddIntoSparseSet(system.Locked, lockedJobs, idsFiltered...)
@@ -166,13 +207,15 @@ func GetFromPool(system *PoolSystem, setBuffer *buffers.SetBuffer[uint64, uint64
 		panic(fmt.Sprintf("Get Timestamps Added %v %v", idsFiltered, getTimestamps))
 	}
 
-	lockTime := ptime.TimeNowInSeconds()
+	lockTime := clock.Now(system.Clock)
 	timestampsLocked := make([]float64, jobsToLockAmount)
 	timeLocked := make([]float64, jobsToLockAmount)
 	for i := range timestampsLocked {
 		timestampsLocked[i] = lockTime
 		timeLocked[i] = lockTime - timestampsAdded[i]
+		system.SpentTimeWaiting += timeLocked[i]
 	}
+	system.LockedAmount += jobsToLockAmount
 
 	addTimestampsLocked := make([]bool, jobsToLockAmount)
 	addTimestampsLocked = sparsemap.SaveIntoSparseMap(system.TimestampsLocked, addTimestampsLocked, idsFiltered, timestampsLocked)
@@ -186,7 +229,9 @@ func GetFromPool(system *PoolSystem, setBuffer *buffers.SetBuffer[uint64, uint64
 		panic(fmt.Sprintf("Added Time Locked %v %v", idsFiltered, lockedJobs))
 	}
 
+	jobs.Transit(system.Jobs, jobs.DispatchedState, idsFiltered...)
 	metrics.AddToMetric(system.Metrics, metrics.JobsLockedCounter, jobsToLockAmount)
+	updateGauges(system)
 
 	logging.GetThenSendInfo(
 		system.Logger,
@@ -232,6 +277,18 @@ func RemoveFromPool(system *PoolSystem, ids ...uint64) {
 
 	removeNodesFromDoublyList(system.Queue, nodesToRemove...)
 
+	removedSeverities := make([]bool, toRemoveAmount)
+	removedSeverities = sparsemap.RemoveFromSparseMap(system.Severities, removedSeverities, idsToRemove...)
+	if bools.AnyFalse(removedSeverities...) {
+		panic(fmt.Sprintf("Removed Severities %v %v", idsToRemove, removedSeverities))
+	}
+
+	removedFirstAlerts := make([]bool, toRemoveAmount)
+	removedFirstAlerts = sparsemap.RemoveFromSparseMap(system.TimestampsFirstAlert, removedFirstAlerts, idsToRemove...)
+	if bools.AnyFalse(removedFirstAlerts...) {
+		panic(fmt.Sprintf("Removed Timestamps First Alert %v %v", idsToRemove, removedFirstAlerts))
+	}
+
 	metrics.AddToMetric(system.Metrics, metrics.JobsUnlockedCounter, toRemoveAmount)
 
 	getTimestamps := make([]bool, toRemoveAmount)
@@ -241,12 +298,22 @@ func RemoveFromPool(system *PoolSystem, ids ...uint64) {
 		panic(fmt.Sprintf("Get Timestamps Locked %v %v", idsToRemove, getTimestamps))
 	}
 
-	timestampPopped := ptime.TimeNowInSeconds()
+	getTimestampsAdded := make([]bool, toRemoveAmount)
+	timestampsAdded := make([]float64, toRemoveAmount)
+	timestampsAdded, getTimestampsAdded = sparsemap.GetFromSparseMap(system.TimestampsAdded, timestampsAdded, getTimestampsAdded, idsToRemove...)
+	if bools.AnyFalse(getTimestampsAdded...) {
+		panic(fmt.Sprintf("Get Timestamps Added %v %v", idsToRemove, getTimestampsAdded))
+	}
+
+	timestampPopped := clock.Now(system.Clock)
 	timestamps := make([]float64, toRemoveAmount)
 	timeSpentHandling := make([]float64, toRemoveAmount)
+	timeSpentInPool := make([]float64, toRemoveAmount)
 	for i := range idsToRemove {
 		timestamps[i] = timestampPopped
 		timeSpentHandling[i] = timestampPopped - timestampsLocked[i]
+		timeSpentInPool[i] = timestampPopped - timestampsAdded[i]
+		system.SpentTimeInPool += timeSpentInPool[i]
 	}
 
 	saveTimestamps := make([]bool, toRemoveAmount)
@@ -256,13 +323,17 @@ func RemoveFromPool(system *PoolSystem, ids ...uint64) {
 	}
 
 	addTimeUnlocked := make([]bool, toRemoveAmount)
-	addTimeUnlocked = sparsemap.SaveIntoSparseMap(system.TimeLocked, addTimeUnlocked, idsToRemove, timeSpentHandling)
+	addTimeUnlocked = sparsemap.SaveIntoSparseMap(system.TimeUnlocked, addTimeUnlocked, idsToRemove, timeSpentHandling)
 	if bools.AnyFalse(addTimeUnlocked...) {
 		panic(fmt.Sprintf("Added Time Unlocked %v %v", idsToRemove, addTimeUnlocked))
 	}
 
 	system.PoppedAmount += toRemoveAmount
 
+	jobs.Transit(system.Jobs, jobs.ResolvedState, idsToRemove...)
+	metrics.ObserveHistogram(system.Metrics, metrics.TimeInPoolHistogram, timeSpentInPool...)
+	updateGauges(system)
+
 	logging.GetThenSendInfo(
 		system.Logger,
 		"removed finished jobs from pool",
@@ -275,6 +346,75 @@ func RemoveFromPool(system *PoolSystem, ids ...uint64) {
 	)
 }
 
+// Timestamps of jobs still in the pool are kept, so jobs finishing after
+// a reset count their whole time in the system.
+func ResetStatistics(system *PoolSystem) {
+	system.Mutex.Lock()
+	defer system.Mutex.Unlock()
+
+	collections.ClearSparseMap(system.TimestampsUnlocked)
+	collections.ClearSparseMap(system.TimeLocked)
+	collections.ClearSparseMap(system.TimeUnlocked)
+
+	system.PoppedAmount = 0
+	system.SpentTimeInPool = 0
+
+	system.LockedAmount = 0
+	system.SpentTimeWaiting = 0
+	system.WaitingArea = 0
+	system.AreaUpdatedAt = clock.Now(system.Clock)
+}
+
+func WaitingAreaUntilNow(system *PoolSystem) float64 {
+	system.Mutex.Lock()
+	defer system.Mutex.Unlock()
+
+	updateWaitingArea(system)
+
+	return system.WaitingArea
+}
+
+// The area under the amount of waiting jobs gives its time average.
+func updateWaitingArea(system *PoolSystem) {
+	now := clock.Now(system.Clock)
+	system.WaitingArea += float64(JobsUnlockedTotal(system)) * (now - system.AreaUpdatedAt)
+	system.AreaUpdatedAt = now
+}
+
+func saveAlertsOrdering(system *PoolSystem, ids []models.AgentId, alertsBatches [][]models.MachineInfo, arePresent []bool) {
+	minLength := min(len(ids), len(alertsBatches))
+	severities := make([]uint8, minLength)
+	gotSeverities := make([]bool, minLength)
+	severities, gotSeverities = sparsemap.GetFromSparseMap(system.Severities, severities, gotSeverities, ids[:minLength]...)
+	timestamps := make([]float64, minLength)
+	gotTimestamps := make([]bool, minLength)
+	timestamps, gotTimestamps = sparsemap.GetFromSparseMap(system.TimestampsFirstAlert, timestamps, gotTimestamps, ids[:minLength]...)
+
+	for i := range minLength {
+		if !arePresent[i] || !gotSeverities[i] || !gotTimestamps[i] {
+			severities[i] = 0
+			timestamps[i] = clock.Now(system.Clock)
+		}
+
+		for _, alert := range alertsBatches[i] {
+			severities[i] = max(severities[i], alert.Severity)
+			timestamps[i] = min(timestamps[i], alert.CreatedAt)
+		}
+	}
+
+	saveSeverities := make([]bool, minLength)
+	saveSeverities = sparsemap.SaveIntoSparseMap(system.Severities, saveSeverities, ids[:minLength], severities)
+	if bools.AnyFalse(saveSeverities...) {
+		panic(fmt.Sprintf("Save Severities %v %v", ids, saveSeverities))
+	}
+
+	saveTimestamps := make([]bool, minLength)
+	saveTimestamps = sparsemap.SaveIntoSparseMap(system.TimestampsFirstAlert, saveTimestamps, ids[:minLength], timestamps)
+	if bools.AnyFalse(saveTimestamps...) {
+		panic(fmt.Sprintf("Save Timestamps First Alert %v %v", ids, saveTimestamps))
+	}
+}
+
 type doublyList struct {
 	Head   *poolNode
 	Tail   *poolNode
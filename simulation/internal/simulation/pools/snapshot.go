@@ -0,0 +1,155 @@
+package pools
+
+import (
+	"StantStantov/ASS/internal/simulation/random"
+	"StantStantov/ASS/internal/simulation/snapshot"
+	"errors"
+	"fmt"
+
+	"github.com/StantStantov/rps/swamp/collections/sparsemap"
+)
+
+type Snapshot struct {
+	Queue   []uint64 `json:"queue"`
+	Present []uint64 `json:"present"`
+	Locked  []uint64 `json:"locked"`
+
+	Severities           snapshot.Entries[uint8]   `json:"severities"`
+	TimestampsFirstAlert snapshot.Entries[float64] `json:"timestamps_first_alert"`
+
+	TimestampsAdded    snapshot.Entries[float64] `json:"timestamps_added"`
+	TimestampsLocked   snapshot.Entries[float64] `json:"timestamps_locked"`
+	TimestampsUnlocked snapshot.Entries[float64] `json:"timestamps_unlocked"`
+
+	TimeLocked   snapshot.Entries[float64] `json:"time_locked"`
+	TimeUnlocked snapshot.Entries[float64] `json:"time_unlocked"`
+
+	PoppedAmount    uint64  `json:"popped_amount"`
+	SpentTimeInPool float64 `json:"spent_time_in_pool"`
+
+	LockedAmount     uint64  `json:"locked_amount"`
+	SpentTimeWaiting float64 `json:"spent_time_waiting"`
+	WaitingArea      float64 `json:"waiting_area"`
+	AreaUpdatedAt    float64 `json:"area_updated_at"`
+
+	Random []byte `json:"random"`
+}
+
+func TakeSnapshot(system *PoolSystem) Snapshot {
+	system.Mutex.Lock()
+	defer system.Mutex.Unlock()
+
+	queue := make([]uint64, 0, system.Queue.Length)
+	for node := system.Queue.Head; node != nil; node = node.Next {
+		queue = append(queue, node.Value)
+	}
+
+	present := make([]uint64, sparsemap.Length(system.Present))
+	present = sparsemap.GetAllKeysFromSparseMap(system.Present, present)
+
+	return Snapshot{
+		Queue:   queue,
+		Present: present,
+		Locked:  snapshot.FromSparseSet(system.Locked),
+
+		Severities:           snapshot.FromSparseMap(system.Severities),
+		TimestampsFirstAlert: snapshot.FromSparseMap(system.TimestampsFirstAlert),
+
+		TimestampsAdded:    snapshot.FromSparseMap(system.TimestampsAdded),
+		TimestampsLocked:   snapshot.FromSparseMap(system.TimestampsLocked),
+		TimestampsUnlocked: snapshot.FromSparseMap(system.TimestampsUnlocked),
+
+		TimeLocked:   snapshot.FromSparseMap(system.TimeLocked),
+		TimeUnlocked: snapshot.FromSparseMap(system.TimeUnlocked),
+
+		PoppedAmount:    system.PoppedAmount,
+		SpentTimeInPool: system.SpentTimeInPool,
+
+		LockedAmount:     system.LockedAmount,
+		SpentTimeWaiting: system.SpentTimeWaiting,
+		WaitingArea:      system.WaitingArea,
+		AreaUpdatedAt:    system.AreaUpdatedAt,
+
+		Random: random.MarshalState(system.Random),
+	}
+}
+
+// ValidateSnapshot reports what RestoreSnapshot can not restore into a pool
+// of capacity jobs.
+func ValidateSnapshot(saved Snapshot, capacity uint64) error {
+	errs := []error{}
+
+	errs = append(errs, snapshot.ValidateKeys("pool queue", saved.Queue, capacity))
+	errs = append(errs, snapshot.ValidateKeys("pool present", saved.Present, capacity))
+	errs = append(errs, snapshot.ValidateKeys("pool locked", saved.Locked, capacity))
+	queued := make(map[uint64]bool, len(saved.Queue))
+	for _, id := range saved.Queue {
+		queued[id] = true
+	}
+	for _, id := range saved.Present {
+		if !queued[id] {
+			errs = append(errs, fmt.Errorf("pool present job %d is not in the queue", id))
+		}
+	}
+
+	errs = append(errs, snapshot.ValidateEntries("pool severities", saved.Severities, capacity))
+	errs = append(errs, snapshot.ValidateEntries("pool timestamps_first_alert", saved.TimestampsFirstAlert, capacity))
+
+	errs = append(errs, snapshot.ValidateEntries("pool timestamps_added", saved.TimestampsAdded, capacity))
+	errs = append(errs, snapshot.ValidateEntries("pool timestamps_locked", saved.TimestampsLocked, capacity))
+	errs = append(errs, snapshot.ValidateEntries("pool timestamps_unlocked", saved.TimestampsUnlocked, capacity))
+
+	errs = append(errs, snapshot.ValidateEntries("pool time_locked", saved.TimeLocked, capacity))
+	errs = append(errs, snapshot.ValidateEntries("pool time_unlocked", saved.TimeUnlocked, capacity))
+
+	if err := random.ValidateState(saved.Random); err != nil {
+		errs = append(errs, fmt.Errorf("pool random: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+func RestoreSnapshot(system *PoolSystem, saved Snapshot) {
+	system.Mutex.Lock()
+	defer system.Mutex.Unlock()
+
+	nodesByIds := make(map[uint64]*poolNode, len(saved.Queue))
+	system.Queue = &doublyList{}
+	for _, id := range saved.Queue {
+		node := &poolNode{Value: id}
+		nodesByIds[id] = node
+		pushNodesIntoDoublyList(system.Queue, node)
+	}
+
+	nodes := make([]*poolNode, len(saved.Present))
+	for i, id := range saved.Present {
+		node, ok := nodesByIds[id]
+		if !ok {
+			panic(fmt.Sprintf("Restore Present %v %v", id, saved.Queue))
+		}
+		nodes[i] = node
+	}
+	snapshot.IntoSparseMap(system.Present, snapshot.Entries[*poolNode]{Keys: saved.Present, Values: nodes})
+	snapshot.IntoSparseSet(system.Locked, saved.Locked)
+
+	snapshot.IntoSparseMap(system.Severities, saved.Severities)
+	snapshot.IntoSparseMap(system.TimestampsFirstAlert, saved.TimestampsFirstAlert)
+
+	snapshot.IntoSparseMap(system.TimestampsAdded, saved.TimestampsAdded)
+	snapshot.IntoSparseMap(system.TimestampsLocked, saved.TimestampsLocked)
+	snapshot.IntoSparseMap(system.TimestampsUnlocked, saved.TimestampsUnlocked)
+
+	snapshot.IntoSparseMap(system.TimeLocked, saved.TimeLocked)
+	snapshot.IntoSparseMap(system.TimeUnlocked, saved.TimeUnlocked)
+
+	system.PoppedAmount = saved.PoppedAmount
+	system.SpentTimeInPool = saved.SpentTimeInPool
+
+	system.LockedAmount = saved.LockedAmount
+	system.SpentTimeWaiting = saved.SpentTimeWaiting
+	system.WaitingArea = saved.WaitingArea
+	system.AreaUpdatedAt = saved.AreaUpdatedAt
+
+	random.UnmarshalState(system.Random, saved.Random)
+	updateGauges(system)
+}
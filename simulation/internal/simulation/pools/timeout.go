@@ -0,0 +1,148 @@
+package pools
+
+import (
+	"StantStantov/ASS/internal/simulation/clock"
+	"StantStantov/ASS/internal/simulation/jobs"
+	"StantStantov/ASS/internal/simulation/metrics"
+	"fmt"
+
+	"github.com/StantStantov/rps/swamp/behaivors/buffers"
+	"github.com/StantStantov/rps/swamp/bools"
+	"github.com/StantStantov/rps/swamp/collections/sparsemap"
+	"github.com/StantStantov/rps/swamp/collections/sparseset"
+	"github.com/StantStantov/rps/swamp/filters"
+	"github.com/StantStantov/rps/swamp/logging"
+	"github.com/StantStantov/rps/swamp/logging/logfmt"
+)
+
+// ExpireFromPool removes the jobs that still wait for a responder Timeout
+// simulated seconds after they were added and returns their ids. Other ids
+// are skipped, so stale timeouts of jobs queued again do nothing.
+func ExpireFromPool(system *PoolSystem, ids ...uint64) []uint64 {
+	system.Mutex.Lock()
+	defer system.Mutex.Unlock()
+
+	if system.Timeout == 0 {
+		return []uint64{}
+	}
+
+	nodes := make([]*poolNode, len(ids))
+	arePresent := make([]bool, len(ids))
+	nodes, arePresent = sparsemap.GetFromSparseMap(system.Present, nodes, arePresent, ids...)
+	areLocked := make([]bool, len(ids))
+	areLocked = sparseset.PresentInSparseSet(system.Locked, areLocked, ids...)
+	timestampsAdded := make([]float64, len(ids))
+	gotTimestamps := make([]bool, len(ids))
+	timestampsAdded, gotTimestamps = sparsemap.GetFromSparseMap(system.TimestampsAdded, timestampsAdded, gotTimestamps, ids...)
+
+	now := clock.Now(system.Clock)
+	areExpired := make([]bool, len(ids))
+	for i := range ids {
+		areExpired[i] = arePresent[i] && !areLocked[i] && gotTimestamps[i] && timestampsAdded[i]+system.Timeout <= now
+	}
+
+	expiredAmount := bools.CountTrue[uint64](areExpired...)
+	idsExpired := make([]uint64, expiredAmount)
+	idsBuffer := &buffers.SetBuffer[uint64, uint64]{Array: idsExpired}
+	filters.KeepIfTrue(idsBuffer, ids, areExpired)
+	nodesExpired := make([]*poolNode, expiredAmount)
+	nodesBuffer := &buffers.SetBuffer[*poolNode, uint64]{Array: nodesExpired}
+	filters.KeepIfTrue(nodesBuffer, nodes, areExpired)
+	if expiredAmount == 0 {
+		return idsExpired
+	}
+
+	updateWaitingArea(system)
+
+	removedFromPresent := make([]bool, expiredAmount)
+	removedFromPresent = sparsemap.RemoveFromSparseMap(system.Present, removedFromPresent, idsExpired...)
+	if bools.AnyFalse(removedFromPresent...) {
+		panic(fmt.Sprintf("Expired From Present %v %v", idsExpired, removedFromPresent))
+	}
+
+	removeNodesFromDoublyList(system.Queue, nodesExpired...)
+
+	removedSeverities := make([]bool, expiredAmount)
+	removedSeverities = sparsemap.RemoveFromSparseMap(system.Severities, removedSeverities, idsExpired...)
+	if bools.AnyFalse(removedSeverities...) {
+		panic(fmt.Sprintf("Expired Severities %v %v", idsExpired, removedSeverities))
+	}
+
+	removedFirstAlerts := make([]bool, expiredAmount)
+	removedFirstAlerts = sparsemap.RemoveFromSparseMap(system.TimestampsFirstAlert, removedFirstAlerts, idsExpired...)
+	if bools.AnyFalse(removedFirstAlerts...) {
+		panic(fmt.Sprintf("Expired Timestamps First Alert %v %v", idsExpired, removedFirstAlerts))
+	}
+
+	jobs.Transit(system.Jobs, jobs.ExpiredState, idsExpired...)
+	metrics.AddToMetric(system.Metrics, metrics.JobsExpiredCounter, expiredAmount)
+	updateGauges(system)
+
+	logging.GetThenSendInfo(
+		system.Logger,
+		"expired waiting jobs in pool",
+		func(event *logging.Event, level logging.Level) error {
+			logfmt.Unsigneds(event, "jobs.ids", idsExpired...)
+			logfmt.Floats64(event, "jobs.timeout", system.Timeout)
+
+			return nil
+		},
+	)
+
+	return idsExpired
+}
+
+// WaitingUntil returns the jobs waiting for a responder that were added at
+// timestamp or earlier, the queue keeps them first.
+func WaitingUntil(system *PoolSystem, timestamp float64) []uint64 {
+	system.Mutex.Lock()
+	defer system.Mutex.Unlock()
+
+	ids := []uint64{}
+	for node := system.Queue.Head; node != nil; node = node.Next {
+		added, ok := timestampAdded(system, node.Value)
+		if !ok || added > timestamp {
+			break
+		}
+		if !isLocked(system, node.Value) {
+			ids = append(ids, node.Value)
+		}
+	}
+
+	return ids
+}
+
+// WaitingSince returns the jobs waiting for a responder that were added at
+// timestamp or later, the queue keeps them last.
+func WaitingSince(system *PoolSystem, timestamp float64) []uint64 {
+	system.Mutex.Lock()
+	defer system.Mutex.Unlock()
+
+	ids := []uint64{}
+	for node := system.Queue.Tail; node != nil; node = node.Prev {
+		added, ok := timestampAdded(system, node.Value)
+		if !ok || added < timestamp {
+			break
+		}
+		if !isLocked(system, node.Value) {
+			ids = append(ids, node.Value)
+		}
+	}
+
+	return ids
+}
+
+func timestampAdded(system *PoolSystem, id uint64) (float64, bool) {
+	timestamps := make([]float64, 1)
+	gotTimestamps := make([]bool, 1)
+	timestamps, gotTimestamps = sparsemap.GetFromSparseMap(system.TimestampsAdded, timestamps, gotTimestamps, id)
+
+	return timestamps[0], gotTimestamps[0]
+}
+
+func isLocked(system *PoolSystem, id uint64) bool {
+	areLocked := make([]bool, 1)
+	areLocked = sparseset.PresentInSparseSet(system.Locked, areLocked, id)
+
+	return areLocked[0]
+}
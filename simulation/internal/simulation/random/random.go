@@ -0,0 +1,87 @@
+package random
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+)
+
+type StreamType uint8
+
+const (
+	AgentsStream StreamType = iota
+	RespondersStream
+	PoolStream
+	SeverityStream
+)
+
+type Generator struct {
+	Seed   uint64
+	Stream StreamType
+
+	Source *rand.PCG
+	Rand   *rand.Rand
+}
+
+func NewGenerator(seed uint64, stream StreamType) *Generator {
+	generator := &Generator{}
+
+	generator.Seed = seed
+	generator.Stream = stream
+
+	generator.Source = rand.NewPCG(seed, streamIncrement(stream))
+	generator.Rand = rand.New(generator.Source)
+
+	return generator
+}
+
+func streamIncrement(stream StreamType) uint64 {
+	const golden = 0x9e3779b97f4a7c15
+
+	return (uint64(stream) + 1) * golden
+}
+
+func Poisson(generator *Generator, lambda float64) uint64 {
+	const maxLambdaStep = 500.0
+
+	amount := uint64(0)
+	for lambda > maxLambdaStep {
+		amount += Poisson(generator, maxLambdaStep)
+		lambda -= maxLambdaStep
+	}
+	if lambda <= 0 {
+		return amount
+	}
+
+	limit := math.Exp(-lambda)
+	product := generator.Rand.Float64()
+	for product > limit {
+		amount++
+		product *= generator.Rand.Float64()
+	}
+
+	return amount
+}
+
+func Exponential(generator *Generator, rate float64) float64 {
+	return generator.Rand.ExpFloat64() / rate
+}
+
+func MarshalState(generator *Generator) []byte {
+	data, err := generator.Source.MarshalBinary()
+	if err != nil {
+		panic(fmt.Sprintf("Marshal Generator State %v", err))
+	}
+
+	return data
+}
+
+func UnmarshalState(generator *Generator, data []byte) {
+	if err := generator.Source.UnmarshalBinary(data); err != nil {
+		panic(fmt.Sprintf("Unmarshal Generator State %v", err))
+	}
+}
+
+func ValidateState(data []byte) error {
+	return (&rand.PCG{}).UnmarshalBinary(data)
+}
@@ -1,6 +1,8 @@
 package responders
 
 import (
+	"StantStantov/ASS/internal/simulation/metrics"
+
 	"github.com/StantStantov/rps/swamp/collections/sparsemap"
 	"github.com/StantStantov/rps/swamp/collections/sparseset"
 )
@@ -12,3 +14,8 @@ func FreeAmount(system *RespondersSystem) uint64 {
 func BusyAmount(system *RespondersSystem) uint64 {
 	return sparsemap.Length(system.Busy)
 }
+
+func updateGauges(system *RespondersSystem) {
+	metrics.SetGauge(system.Metrics, metrics.RespondersFreeGauge, FreeAmount(system))
+	metrics.SetGauge(system.Metrics, metrics.RespondersBusyGauge, BusyAmount(system))
+}
@@ -0,0 +1,119 @@
+package responders
+
+import (
+	"StantStantov/ASS/internal/simulation/models"
+	"StantStantov/ASS/internal/simulation/snapshot"
+	"fmt"
+
+	"github.com/StantStantov/rps/swamp/bools"
+	"github.com/StantStantov/rps/swamp/collections/sparsemap"
+	"github.com/StantStantov/rps/swamp/collections/sparseset"
+	"github.com/StantStantov/rps/swamp/logging"
+	"github.com/StantStantov/rps/swamp/logging/logfmt"
+)
+
+// Resize changes the amount of active responders. Busy responders beyond
+// the new amount finish their current job before they retire.
+func Resize(system *RespondersSystem, amount uint64, priorities []uint64) {
+	if uint64(len(priorities)) != amount {
+		panic(fmt.Sprintf("Resize Responders Priorities %v %v", amount, priorities))
+	}
+
+	Grow(system, amount)
+
+	amountBefore := uint64(len(system.Responders))
+	system.Responders = make([]models.ResponderId, amount)
+	for i := range system.Responders {
+		system.Responders[i] = models.ResponderId(i)
+		system.RespondersInfo[i] = models.ResponderInfo{Priority: priorities[i]}
+	}
+
+	retired := []models.ResponderId{}
+	for id := amount; id < amountBefore; id++ {
+		retired = append(retired, id)
+	}
+	areFree := make([]bool, len(retired))
+	areFree = sparseset.PresentInSparseSet(system.Free, areFree, retired...)
+	retiredFree := []models.ResponderId{}
+	for i, isFree := range areFree {
+		if isFree {
+			retiredFree = append(retiredFree, retired[i])
+		}
+	}
+	removedFromFree := make([]bool, len(retiredFree))
+	removedFromFree = sparseset.RemoveFromSparseSet(system.Free, removedFromFree, retiredFree...)
+	if bools.AnyFalse(removedFromFree...) {
+		panic(fmt.Sprintf("Remove Retired from Free %v %v", retiredFree, removedFromFree))
+	}
+
+	// A responder added back while still busy from an earlier shrink
+	// becomes free when its job is released.
+	hired := []models.ResponderId{}
+	for id := amountBefore; id < amount; id++ {
+		hired = append(hired, id)
+	}
+	areBusy := make([]bool, len(hired))
+	areBusy = sparsemap.PresentInSparseMap(system.Busy, areBusy, hired...)
+	hiredFree := []models.ResponderId{}
+	for i, isBusy := range areBusy {
+		if !isBusy {
+			hiredFree = append(hiredFree, hired[i])
+		}
+	}
+	addedToFree := make([]bool, len(hiredFree))
+	addedToFree = sparseset.AddIntoSparseSet(system.Free, addedToFree, hiredFree...)
+	if bools.AnyFalse(addedToFree...) {
+		panic(fmt.Sprintf("Add Hired to Free %v %v", hiredFree, addedToFree))
+	}
+
+	updateGauges(system)
+
+	logging.GetThenSendInfo(
+		system.Logger,
+		"resized responders",
+		func(event *logging.Event, level logging.Level) error {
+			logfmt.Unsigned(event, "responders.amount", amount)
+			logfmt.Unsigneds(event, "responders.hired.ids", hired...)
+			logfmt.Unsigneds(event, "responders.retired.ids", retired...)
+
+			return nil
+		},
+	)
+}
+
+// Grow raises the capacity of every per responder structure, it never
+// shrinks them so retired responders keep their statistics.
+func Grow(system *RespondersSystem, capacity uint64) {
+	capacityBefore := uint64(len(system.Handled))
+	if capacity <= capacityBefore {
+		return
+	}
+
+	extra := capacity - capacityBefore
+	system.RespondersInfo = append(system.RespondersInfo, make([]models.ResponderInfo, extra)...)
+	system.Handled = append(system.Handled, make([]uint64, extra)...)
+	system.All = append(system.All, make([]uint64, extra)...)
+	system.TimeBusy = append(system.TimeBusy, make([]float64, extra)...)
+
+	free := snapshot.FromSparseSet(system.Free)
+	system.Free = sparseset.NewSparseSet(capacity)
+	snapshot.IntoSparseSet(system.Free, free)
+
+	system.Busy = growSparseMap(system.Busy, capacity)
+	system.TimestampsLocked = growSparseMap(system.TimestampsLocked, capacity)
+	system.TimestampsUnlocked = growSparseMap(system.TimestampsUnlocked, capacity)
+	system.TimeUnlocked = growSparseMap(system.TimeUnlocked, capacity)
+	system.Deadlines = growSparseMap(system.Deadlines, capacity)
+}
+
+func growSparseMap[V any](sparseMap *sparsemap.SparseMap[uint64, V], capacity uint64) *sparsemap.SparseMap[uint64, V] {
+	entries := snapshot.FromSparseMap(sparseMap)
+	grown := sparsemap.NewSparseMap[uint64, V](capacity)
+	snapshot.IntoSparseMap(grown, entries)
+
+	return grown
+}
+
+func isActive(system *RespondersSystem, id models.ResponderId) bool {
+	return id < uint64(len(system.Responders))
+}
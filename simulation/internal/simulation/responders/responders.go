@@ -1,12 +1,16 @@
 package responders
 
 import (
-	ptime "StantStantov/ASS/internal/common/time"
+	"StantStantov/ASS/internal/common/collections"
+	"StantStantov/ASS/internal/simulation/clock"
 	"StantStantov/ASS/internal/simulation/dispatchers"
+	"StantStantov/ASS/internal/simulation/jobs"
 	"StantStantov/ASS/internal/simulation/metrics"
 	"StantStantov/ASS/internal/simulation/models"
+	"StantStantov/ASS/internal/simulation/random"
+	"cmp"
 	"fmt"
-	"math/rand"
+	"slices"
 
 	"github.com/StantStantov/rps/swamp/behaivors/buffers"
 	"github.com/StantStantov/rps/swamp/bools"
@@ -17,10 +21,14 @@ import (
 	"github.com/StantStantov/rps/swamp/logging/logfmt"
 )
 
+const deadlineTolerance = 1e-9
+
 type RespondersSystem struct {
 	Responders        []models.ResponderId
 	RespondersInfo    []models.ResponderInfo
 	MinChanceToHandle float32
+	ServiceLaw        ServiceLaw
+	ServiceParameters ServiceParameters
 
 	Dispatcher *dispatchers.DispatchSystem
 
@@ -29,18 +37,28 @@ type RespondersSystem struct {
 
 	Handled            []uint64
 	All                []uint64
+	TimeBusy           []float64
+	CountedFrom        float64
 	TimestampsLocked   *sparsemap.SparseMap[uint64, float64]
 	TimestampsUnlocked *sparsemap.SparseMap[uint64, float64]
 	TimeUnlocked       *sparsemap.SparseMap[uint64, float64]
+	Deadlines          *sparsemap.SparseMap[uint64, float64]
 
+	Clock   *clock.ClockSystem
+	Random  *random.Generator
 	Metrics *metrics.MetricsSystem
 	Logger  *logging.Logger
 }
 
 func NewRespondersSystem(
 	capacity uint64,
+	priorities []uint64,
 	minChanceToHandle float32,
+	serviceLaw ServiceLaw,
+	serviceParameters ServiceParameters,
 	dispatcher *dispatchers.DispatchSystem,
+	clock *clock.ClockSystem,
+	random *random.Generator,
 	metrics *metrics.MetricsSystem,
 	logger *logging.Logger,
 ) *RespondersSystem {
@@ -50,9 +68,11 @@ func NewRespondersSystem(
 	system.RespondersInfo = make([]models.ResponderInfo, capacity)
 	for i := range system.Responders {
 		system.Responders[i] = models.ResponderId(i)
-		system.RespondersInfo[i] = models.ResponderInfo{}
+		system.RespondersInfo[i] = models.ResponderInfo{Priority: priorities[i]}
 	}
 	system.MinChanceToHandle = minChanceToHandle
+	system.ServiceLaw = serviceLaw
+	system.ServiceParameters = serviceParameters
 
 	system.Free = sparseset.NewSparseSet(capacity)
 	system.Busy = sparsemap.NewSparseMap[models.ResponderId, models.Job](capacity)
@@ -64,22 +84,36 @@ func NewRespondersSystem(
 
 	system.Handled = make([]uint64, capacity)
 	system.All = make([]uint64, capacity)
+	system.TimeBusy = make([]float64, capacity)
+	system.CountedFrom = 0
 	system.TimestampsLocked = sparsemap.NewSparseMap[uint64, float64](capacity)
 	system.TimestampsUnlocked = sparsemap.NewSparseMap[uint64, float64](capacity)
 	system.TimeUnlocked = sparsemap.NewSparseMap[uint64, float64](capacity)
+	system.Deadlines = sparsemap.NewSparseMap[uint64, float64](capacity)
 
+	system.Clock = clock
+	system.Random = random
 	system.Metrics = metrics
 	system.Logger = logging.NewChildLogger(logger, func(event *logging.Event) {
 		logfmt.String(event, "from", "responders_system")
 	})
+	updateGauges(system)
 
 	return system
 }
 
 func ProcessRespondersSystem(system *RespondersSystem) {
+	AssignJobs(system)
+	respondersFreed := PollFreed(system)
+	ReleaseResponders(system, respondersFreed...)
+	SampleResponders(system)
+}
+
+func AssignJobs(system *RespondersSystem) []models.ResponderId {
 	amountFree := sparseset.Length(system.Free)
 	respondersFree := make([]models.ResponderId, amountFree)
 	respondersFree = sparseset.GetAllFromSparseSet(system.Free, respondersFree)
+	SortByPriority(system, respondersFree)
 
 	jobsToGet := make([]models.Job, amountFree)
 	jobsToGetBuffer := &buffers.SetBuffer[models.Job, uint64]{Array: jobsToGet}
@@ -101,7 +135,7 @@ func ProcessRespondersSystem(system *RespondersSystem) {
 		panic(fmt.Sprintf("Add Busyed to Busy %v %v", respondersToBusy, addedToBusy))
 	}
 
-	lockTime := ptime.TimeNowInSeconds()
+	lockTime := clock.Now(system.Clock)
 	timestampsLocked := make([]float64, minLength)
 	for i := range timestampsLocked {
 		timestampsLocked[i] = lockTime
@@ -113,13 +147,26 @@ func ProcessRespondersSystem(system *RespondersSystem) {
 		panic(fmt.Sprintf("Added Timestamps Locked %v %v", respondersToBusy, addTimestampsLocked))
 	}
 
+	deadlines := make([]float64, minLength)
+	for i, job := range jobsToBusy {
+		deadlines[i] = lockTime + SampleServiceTime(system, job)
+	}
+
+	jobsIds := make([]uint64, minLength)
+	jobsIds = models.JobsToIds(jobsToBusy, jobsIds)
+	jobs.Transit(system.Dispatcher.AlertsPool.Jobs, jobs.InProgressState, jobsIds...)
+	updateGauges(system)
+
+	addDeadlines := make([]bool, minLength)
+	addDeadlines = sparsemap.SaveIntoSparseMap(system.Deadlines, addDeadlines, respondersToBusy, deadlines)
+	if bools.AnyFalse(addDeadlines...) {
+		panic(fmt.Sprintf("Added Deadlines %v %v", respondersToBusy, addDeadlines))
+	}
+
 	logging.GetThenSendInfo(
 		system.Logger,
 		"gave free responders new jobs",
 		func(event *logging.Event, level logging.Level) error {
-			jobsIds := make([]uint64, len(jobsToBusy))
-			jobsIds = models.JobsToIds(jobsToBusy, jobsIds)
-
 			logfmt.Unsigneds(event, "responders.ids", respondersToBusy...)
 			logfmt.Unsigneds(event, "jobs.ids", jobsIds...)
 
@@ -127,15 +174,28 @@ func ProcessRespondersSystem(system *RespondersSystem) {
 		},
 	)
 
+	return respondersToBusy
+}
+
+func PollFreed(system *RespondersSystem) []models.ResponderId {
 	amountBusy := sparsemap.Length(system.Busy)
 	idsBusy := make([]models.ResponderId, amountBusy)
 	idsBusy = sparsemap.GetAllKeysFromSparseMap(system.Busy, idsBusy)
 
 	areFreed := make([]bool, amountBusy)
-	for i := range amountBusy {
-		currentChance := rand.Float32()
-		free := currentChance >= system.MinChanceToHandle
-		areFreed[i] = free
+	if system.ServiceLaw == ChanceService {
+		for i := range amountBusy {
+			currentChance := system.Random.Rand.Float32()
+			free := currentChance >= system.MinChanceToHandle
+			areFreed[i] = free
+		}
+	} else {
+		busyDeadlines := DeadlinesOf(system, idsBusy...)
+
+		now := clock.Now(system.Clock)
+		for i, deadline := range busyDeadlines {
+			areFreed[i] = now+deadlineTolerance >= deadline
+		}
 	}
 
 	amountFreed, amountStillBusy := bools.CountBools[models.ResponderId, models.ResponderId](areFreed...)
@@ -145,6 +205,21 @@ func ProcessRespondersSystem(system *RespondersSystem) {
 	freedBuffer := &buffers.SetBuffer[models.ResponderId, uint64]{Array: respondersFreed}
 	filters.SeparateByBools(stillBusyBuffer, freedBuffer, idsBusy, areFreed)
 
+	logging.GetThenSendInfo(
+		system.Logger,
+		"polled responders for statuses",
+		func(event *logging.Event, level logging.Level) error {
+			logfmt.Unsigneds(event, "responders.freed.ids", respondersFreed...)
+			logfmt.Unsigneds(event, "responders.still_busy.ids", respondersStillBusy...)
+
+			return nil
+		},
+	)
+
+	return respondersFreed
+}
+
+func ReleaseResponders(system *RespondersSystem, respondersFreed ...models.ResponderId) {
 	jobsToFree := make([]models.Job, len(respondersFreed))
 	gotJobsToFree := make([]bool, len(respondersFreed))
 	jobsToFree, gotJobsToFree = sparsemap.GetFromSparseMap(system.Busy, jobsToFree, gotJobsToFree, respondersFreed...)
@@ -160,15 +235,19 @@ func ProcessRespondersSystem(system *RespondersSystem) {
 		panic(fmt.Sprintf("Remove Freed From Busy %v %v", respondersFreed, oksRemovedFreed))
 	}
 
-	oksAddedFreed := make([]bool, len(respondersFreed))
-	oksAddedFreed = sparseset.AddIntoSparseSet(system.Free, oksAddedFreed, respondersFreed...)
-	if bools.AnyFalse(oksAddedFreed...) {
-		panic(fmt.Sprintf("Add Freed To Free %v %v", respondersFreed, oksAddedFreed))
+	respondersActive := make([]models.ResponderId, 0, len(respondersFreed))
+	for _, id := range respondersFreed {
+		if isActive(system, id) {
+			respondersActive = append(respondersActive, id)
+		}
 	}
 
-	for _, id := range system.Responders {
-		system.All[id]++
+	oksAddedFreed := make([]bool, len(respondersActive))
+	oksAddedFreed = sparseset.AddIntoSparseSet(system.Free, oksAddedFreed, respondersActive...)
+	if bools.AnyFalse(oksAddedFreed...) {
+		panic(fmt.Sprintf("Add Freed To Free %v %v", respondersActive, oksAddedFreed))
 	}
+
 	for _, id := range respondersFreed {
 		system.Handled[id]++
 	}
@@ -180,37 +259,103 @@ func ProcessRespondersSystem(system *RespondersSystem) {
 		panic(fmt.Sprintf("Get Timestamps Locked AGAIN %v %v", respondersFreed, getTimestamps))
 	}
 
-	unlockTime := ptime.TimeNowInSeconds()
+	unlockTime := clock.Now(system.Clock)
 	timestampsUnlocked := make([]float64, len(respondersFreed))
 	timeSpentHandling := make([]float64, len(respondersFreed))
-	for i := range timestampsUnlocked {
+	for i, id := range respondersFreed {
 		timestampsUnlocked[i] = unlockTime
 		timeSpentHandling[i] = unlockTime - timestampsLockedAgain[i]
+		system.TimeBusy[id] += unlockTime - max(timestampsLockedAgain[i], system.CountedFrom)
 	}
 
-	addTimestampsUnlocked := make([]bool, minLength)
+	addTimestampsUnlocked := make([]bool, len(respondersFreed))
 	addTimestampsUnlocked = sparsemap.SaveIntoSparseMap(system.TimestampsUnlocked, addTimestampsUnlocked, respondersFreed, timestampsUnlocked)
 	if bools.AnyFalse(addTimestampsUnlocked...) {
 		panic(fmt.Sprintf("Added Timestamps Unlocked %v %v", respondersFreed, addTimestampsUnlocked))
 	}
 
-	addTimeUnlocked := make([]bool, minLength)
+	addTimeUnlocked := make([]bool, len(respondersFreed))
 	addTimeUnlocked = sparsemap.SaveIntoSparseMap(system.TimeUnlocked, addTimeUnlocked, respondersFreed, timeSpentHandling)
-	if bools.AnyFalse(addTimestampsUnlocked...) {
-		panic(fmt.Sprintf("Added Timestamps Unlocked %v %v", respondersFreed, addTimeUnlocked))
+	if bools.AnyFalse(addTimeUnlocked...) {
+		panic(fmt.Sprintf("Added Time Unlocked %v %v", respondersFreed, addTimeUnlocked))
 	}
 
-	metrics.AddToMetric(system.Metrics, metrics.RespondersFreeCounter, FreeAmount(system))
-	metrics.AddToMetric(system.Metrics, metrics.RespondersBusyCounter, BusyAmount(system))
+	metrics.ObserveHistogram(system.Metrics, metrics.ServiceTimeHistogram, timeSpentHandling...)
+	updateGauges(system)
 
 	logging.GetThenSendInfo(
 		system.Logger,
-		"polled responders for statuses",
+		"released responders from finished jobs",
 		func(event *logging.Event, level logging.Level) error {
-			logfmt.Unsigneds(event, "responders.freed.ids", respondersFreed...)
-			logfmt.Unsigneds(event, "responders.still_busy.ids", respondersStillBusy...)
+			jobsIds := make([]uint64, len(jobsToFree))
+			jobsIds = models.JobsToIds(jobsToFree, jobsIds)
+
+			logfmt.Unsigneds(event, "responders.ids", respondersFreed...)
+			logfmt.Unsigneds(event, "jobs.ids", jobsIds...)
+			logfmt.Floats64(event, "jobs.time", timeSpentHandling...)
 
 			return nil
 		},
 	)
 }
+
+func SampleResponders(system *RespondersSystem) {
+	for _, id := range system.Responders {
+		system.All[id]++
+	}
+}
+
+// Busy time of jobs started before a reset is counted from the reset.
+func ResetStatistics(system *RespondersSystem) {
+	clear(system.Handled)
+	clear(system.All)
+	clear(system.TimeBusy)
+	collections.ClearSparseMap(system.TimestampsUnlocked)
+	collections.ClearSparseMap(system.TimeUnlocked)
+
+	system.CountedFrom = clock.Now(system.Clock)
+}
+
+func SortByPriority(system *RespondersSystem, ids []models.ResponderId) {
+	slices.SortFunc(ids, func(a, b models.ResponderId) int {
+		priorityA := system.RespondersInfo[a].Priority
+		priorityB := system.RespondersInfo[b].Priority
+		if priorityA != priorityB {
+			return cmp.Compare(priorityB, priorityA)
+		}
+
+		return cmp.Compare(a, b)
+	})
+}
+
+func DeadlinesOf(system *RespondersSystem, ids ...models.ResponderId) []float64 {
+	deadlines := make([]float64, len(ids))
+	gotDeadlines := make([]bool, len(ids))
+	deadlines, gotDeadlines = sparsemap.GetFromSparseMap(system.Deadlines, deadlines, gotDeadlines, ids...)
+	if bools.AnyFalse(gotDeadlines...) {
+		panic(fmt.Sprintf("Get Deadlines %v %v", ids, gotDeadlines))
+	}
+
+	return deadlines
+}
+
+// TimesBusy adds the time busy responders have spent on their current jobs
+// so far to the busy time of their finished ones.
+func TimesBusy(system *RespondersSystem, ids ...models.ResponderId) []float64 {
+	areBusy := make([]bool, len(ids))
+	areBusy = sparsemap.PresentInSparseMap(system.Busy, areBusy, ids...)
+	timestampsLocked := make([]float64, len(ids))
+	gotTimestamps := make([]bool, len(ids))
+	timestampsLocked, gotTimestamps = sparsemap.GetFromSparseMap(system.TimestampsLocked, timestampsLocked, gotTimestamps, ids...)
+
+	now := clock.Now(system.Clock)
+	timesBusy := make([]float64, len(ids))
+	for i, id := range ids {
+		timesBusy[i] = system.TimeBusy[id]
+		if areBusy[i] && gotTimestamps[i] {
+			timesBusy[i] += now - max(timestampsLocked[i], system.CountedFrom)
+		}
+	}
+
+	return timesBusy
+}
@@ -0,0 +1,74 @@
+package responders
+
+import (
+	"StantStantov/ASS/internal/simulation/models"
+	"StantStantov/ASS/internal/simulation/random"
+	"slices"
+)
+
+type ServiceLaw uint8
+
+const (
+	ChanceService ServiceLaw = iota
+	ExponentialService
+	UniformService
+	ErlangService
+	DeterministicService
+	EmpiricalService
+	PerAlertService
+)
+
+var ServiceLawsNames = []string{
+	"chance",
+	"exponential",
+	"uniform",
+	"erlang",
+	"deterministic",
+	"empirical",
+	"per_alert",
+}
+
+type ServiceParameters struct {
+	Rate     float64
+	Min      float64
+	Max      float64
+	Phases   uint64
+	Duration float64
+	Samples  []float64
+}
+
+func ServiceLawFromName(name string) (ServiceLaw, bool) {
+	index := slices.Index(ServiceLawsNames, name)
+	if index < 0 {
+		return ChanceService, false
+	}
+
+	return ServiceLaw(index), true
+}
+
+func SampleServiceTime(system *RespondersSystem, job models.Job) float64 {
+	parameters := &system.ServiceParameters
+	switch system.ServiceLaw {
+	case ExponentialService:
+		return random.Exponential(system.Random, parameters.Rate)
+	case UniformService:
+		return parameters.Min + system.Random.Rand.Float64()*(parameters.Max-parameters.Min)
+	case ErlangService:
+		serviceTime := 0.0
+		for range parameters.Phases {
+			serviceTime += random.Exponential(system.Random, parameters.Rate)
+		}
+
+		return serviceTime
+	case DeterministicService:
+		return parameters.Duration
+	case EmpiricalService:
+		index := system.Random.Rand.IntN(len(parameters.Samples))
+
+		return parameters.Samples[index]
+	case PerAlertService:
+		return float64(len(job.Alerts)) * parameters.Duration
+	}
+
+	return 0
+}
@@ -0,0 +1,89 @@
+package responders
+
+import (
+	"StantStantov/ASS/internal/simulation/models"
+	"StantStantov/ASS/internal/simulation/random"
+	"StantStantov/ASS/internal/simulation/snapshot"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+type Snapshot struct {
+	Free []models.ResponderId         `json:"free"`
+	Busy snapshot.Entries[models.Job] `json:"busy"`
+
+	Handled            []uint64                  `json:"handled"`
+	All                []uint64                  `json:"all"`
+	TimeBusy           []float64                 `json:"time_busy"`
+	CountedFrom        float64                   `json:"counted_from"`
+	TimestampsLocked   snapshot.Entries[float64] `json:"timestamps_locked"`
+	TimestampsUnlocked snapshot.Entries[float64] `json:"timestamps_unlocked"`
+	TimeUnlocked       snapshot.Entries[float64] `json:"time_unlocked"`
+	Deadlines          snapshot.Entries[float64] `json:"deadlines"`
+
+	Random []byte `json:"random"`
+}
+
+func TakeSnapshot(system *RespondersSystem) Snapshot {
+	return Snapshot{
+		Free: snapshot.FromSparseSet(system.Free),
+		Busy: snapshot.FromSparseMap(system.Busy),
+
+		Handled:            slices.Clone(system.Handled),
+		All:                slices.Clone(system.All),
+		TimeBusy:           slices.Clone(system.TimeBusy),
+		CountedFrom:        system.CountedFrom,
+		TimestampsLocked:   snapshot.FromSparseMap(system.TimestampsLocked),
+		TimestampsUnlocked: snapshot.FromSparseMap(system.TimestampsUnlocked),
+		TimeUnlocked:       snapshot.FromSparseMap(system.TimeUnlocked),
+		Deadlines:          snapshot.FromSparseMap(system.Deadlines),
+
+		Random: random.MarshalState(system.Random),
+	}
+}
+
+// ValidateSnapshot reports what RestoreSnapshot can not restore, the
+// responders are grown to as many as the snapshot has.
+func ValidateSnapshot(saved Snapshot) error {
+	errs := []error{}
+
+	capacity := uint64(len(saved.Handled))
+	if len(saved.All) != len(saved.Handled) || len(saved.TimeBusy) != len(saved.Handled) {
+		errs = append(errs, fmt.Errorf(
+			"responders have %d handled, %d all and %d time_busy counts",
+			len(saved.Handled), len(saved.All), len(saved.TimeBusy),
+		))
+	}
+	errs = append(errs, snapshot.ValidateKeys("responders free", saved.Free, capacity))
+	errs = append(errs, snapshot.ValidateEntries("responders busy", saved.Busy, capacity))
+
+	errs = append(errs, snapshot.ValidateEntries("responders timestamps_locked", saved.TimestampsLocked, capacity))
+	errs = append(errs, snapshot.ValidateEntries("responders timestamps_unlocked", saved.TimestampsUnlocked, capacity))
+	errs = append(errs, snapshot.ValidateEntries("responders time_unlocked", saved.TimeUnlocked, capacity))
+	errs = append(errs, snapshot.ValidateEntries("responders deadlines", saved.Deadlines, capacity))
+
+	if err := random.ValidateState(saved.Random); err != nil {
+		errs = append(errs, fmt.Errorf("responders random: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+func RestoreSnapshot(system *RespondersSystem, saved Snapshot) {
+	Grow(system, uint64(len(saved.Handled)))
+	snapshot.IntoSparseSet(system.Free, saved.Free)
+	snapshot.IntoSparseMap(system.Busy, saved.Busy)
+
+	copy(system.Handled, saved.Handled)
+	copy(system.All, saved.All)
+	copy(system.TimeBusy, saved.TimeBusy)
+	system.CountedFrom = saved.CountedFrom
+	snapshot.IntoSparseMap(system.TimestampsLocked, saved.TimestampsLocked)
+	snapshot.IntoSparseMap(system.TimestampsUnlocked, saved.TimestampsUnlocked)
+	snapshot.IntoSparseMap(system.TimeUnlocked, saved.TimeUnlocked)
+	snapshot.IntoSparseMap(system.Deadlines, saved.Deadlines)
+
+	random.UnmarshalState(system.Random, saved.Random)
+	updateGauges(system)
+}
@@ -0,0 +1,110 @@
+package simulation
+
+import (
+	"StantStantov/ASS/internal/simulation/agents"
+	"StantStantov/ASS/internal/simulation/clock"
+	"StantStantov/ASS/internal/simulation/dispatchers"
+	"StantStantov/ASS/internal/simulation/events"
+	"StantStantov/ASS/internal/simulation/models"
+	"StantStantov/ASS/internal/simulation/pools"
+	"StantStantov/ASS/internal/simulation/responders"
+	"fmt"
+)
+
+func scheduleInitialEvents() {
+	now := clock.Now(Clock)
+	events.Schedule(EventsSystem, now+MsPerUpdate, events.TickEvent, 0)
+
+	if AgentsSystem.ArrivalModel == agents.BernoulliArrival {
+		return
+	}
+	for _, id := range AgentsSystem.AgentsIds {
+		delay := agents.NextArrivalDelay(AgentsSystem, id)
+		events.Schedule(EventsSystem, now+delay, events.ArrivalEvent, id)
+	}
+}
+
+func processEventsUntilTick() {
+	for {
+		event, ok := events.Pop(EventsSystem)
+		if !ok {
+			panic("Events Queue is empty")
+		}
+
+		clock.SetTo(Clock, event.Timestamp)
+		switch event.Type {
+		case events.TickEvent:
+			handleTickEvent()
+
+			return
+		case events.ArrivalEvent:
+			handleArrivalEvent(event.Id)
+		case events.CompletionEvent:
+			handleCompletionEvent(event.Id)
+		case events.TimeoutEvent:
+			handleTimeoutEvent(event.Id)
+		default:
+			panic(fmt.Sprintf("Unknown Event Type %v", event))
+		}
+	}
+}
+
+func handleTickEvent() {
+	if AgentsSystem.ArrivalModel == agents.BernoulliArrival {
+		agents.ProcessAgentSystem(AgentsSystem)
+		scheduleTimeouts()
+	}
+	assignJobs()
+	if RespondersSystem.ServiceLaw == responders.ChanceService {
+		respondersFreed := responders.PollFreed(RespondersSystem)
+		responders.ReleaseResponders(RespondersSystem, respondersFreed...)
+	}
+	responders.SampleResponders(RespondersSystem)
+
+	finishTick()
+
+	events.Schedule(EventsSystem, clock.Now(Clock)+MsPerUpdate, events.TickEvent, 0)
+}
+
+func handleArrivalEvent(id models.AgentId) {
+	agents.EmitAlerts(AgentsSystem, []models.AgentId{id}, []uint64{1})
+	scheduleTimeouts()
+	assignJobs()
+
+	delay := agents.NextArrivalDelay(AgentsSystem, id)
+	events.Schedule(EventsSystem, clock.Now(Clock)+delay, events.ArrivalEvent, id)
+}
+
+func handleCompletionEvent(id models.ResponderId) {
+	responders.ReleaseResponders(RespondersSystem, id)
+	assignJobs()
+}
+
+func handleTimeoutEvent(id uint64) {
+	dispatchers.ExpireJobs(DispatchSystem, id)
+}
+
+// Every job queued right now expires after the timeout unless a responder
+// takes it first.
+func scheduleTimeouts() {
+	if Pool.Timeout == 0 {
+		return
+	}
+
+	now := clock.Now(Clock)
+	for _, id := range pools.WaitingSince(Pool, now) {
+		events.Schedule(EventsSystem, now+Pool.Timeout, events.TimeoutEvent, id)
+	}
+}
+
+func assignJobs() {
+	respondersBusied := responders.AssignJobs(RespondersSystem)
+	if RespondersSystem.ServiceLaw == responders.ChanceService {
+		return
+	}
+
+	deadlines := responders.DeadlinesOf(RespondersSystem, respondersBusied...)
+	for i, id := range respondersBusied {
+		events.Schedule(EventsSystem, deadlines[i], events.CompletionEvent, id)
+	}
+}
@@ -2,19 +2,28 @@ package simulation
 
 import (
 	ptime "StantStantov/ASS/internal/common/time"
+	"StantStantov/ASS/internal/config"
 	"StantStantov/ASS/internal/simulation/agents"
 	"StantStantov/ASS/internal/simulation/buffer"
+	"StantStantov/ASS/internal/simulation/clock"
 	"StantStantov/ASS/internal/simulation/commands"
 	"StantStantov/ASS/internal/simulation/dispatchers"
-	"StantStantov/ASS/internal/simulation/framebuffer"
+	"StantStantov/ASS/internal/simulation/events"
+	"StantStantov/ASS/internal/simulation/jobs"
+	"StantStantov/ASS/internal/simulation/loghistory"
 	"StantStantov/ASS/internal/simulation/metrics"
 	"StantStantov/ASS/internal/simulation/pools"
+	"StantStantov/ASS/internal/simulation/random"
 	"StantStantov/ASS/internal/simulation/responders"
+	"StantStantov/ASS/internal/simulation/timeseries"
+	"StantStantov/ASS/internal/simulation/warmup"
 
 	"github.com/StantStantov/rps/swamp/logging"
+	"github.com/StantStantov/rps/swamp/logging/logfmt"
 )
 
 var (
+	Clock            *clock.ClockSystem           = nil
 	Buffer           *buffer.BufferSystem         = nil
 	Pool             *pools.PoolSystem            = nil
 	CommandsSystem   *commands.CommandsSystem     = nil
@@ -22,36 +31,65 @@ var (
 	AgentsSystem     *agents.AgentSystem          = nil
 	RespondersSystem *responders.RespondersSystem = nil
 	MetricsSystem    *metrics.MetricsSystem       = nil
+	EventsSystem     *events.EventsSystem         = nil
+	JobsSystem       *jobs.JobsSystem             = nil
+	WarmupSystem     *warmup.WarmupSystem         = nil
+	TimeSeries       *timeseries.TimeSeriesSystem = nil
 
-	Logbuffer *framebuffer.Buffer = nil
+	LogHistory *loghistory.History = nil
+	Config     *config.Config      = nil
+	Logger     *logging.Logger     = nil
 
-	MsPerUpdate float64 = 1.000
-	IsPaused    bool    = true
-	TickCounter uint64  = 0
+	Seed          uint64        = 0
+	CurrentEngine events.Engine = events.TickEngine
+	MsPerUpdate   float64       = 1.000
+	SpeedIndex    int           = defaultSpeedIndex
+	IsPaused      bool          = true
+	TickCounter   uint64        = 0
 )
 
+// SpeedLevels multiply how many ticks run per real second, the simulated time
+// of a tick stays MsPerUpdate.
+var SpeedLevels = []float64{0.25, 0.5, 1, 2, 4, 8, 16, 32}
+
+const defaultSpeedIndex = 2
+
+// The event loop sleeps until the next tick is due, but wakes up at least
+// this often in real seconds to handle commands.
+const commandsPollInterval = 0.050
+
 func Init(
-	msPerUpdate float64,
-	agentsAmount uint64,
-	respondersAmount uint64,
-	chanceToCrash float32,
-	alertsCapacity uint64,
-	chanceToHandle float32,
-	logbuffer *framebuffer.Buffer,
+	cfg *config.Config,
+	logHistory *loghistory.History,
 	logger *logging.Logger,
 ) {
 	commandsSystem := commands.NewCommandsSystem()
+	eventsSystem := events.NewEventsSystem()
+	clockSystem := clock.NewClockSystem()
 	metricsSystem := metrics.NewMetricsSystem(
+		clockSystem,
 		logger,
 	)
 	bufferSystem := buffer.NewBufferSystem(
-		agentsAmount,
-		alertsCapacity,
+		cfg.AgentsAmount,
+		cfg.AlertsCapacity,
+		config.RefusalPolicy(cfg),
+		cfg.OverflowCapacity,
 		metricsSystem,
 		logger,
 	)
+	jobsSystem := jobs.NewJobsSystem(
+		cfg.AgentsAmount,
+		clockSystem,
+		logger,
+	)
 	poolSystem := pools.NewPoolSystem(
-		agentsAmount,
+		cfg.AgentsAmount,
+		config.QueueDiscipline(cfg),
+		cfg.JobTimeout,
+		jobsSystem,
+		clockSystem,
+		random.NewGenerator(cfg.Seed, random.PoolStream),
 		metricsSystem,
 		logger,
 	)
@@ -62,20 +100,42 @@ func Init(
 		logger,
 	)
 	agentsSystem := agents.NewAgentSystem(
-		agentsAmount,
-		chanceToCrash,
+		cfg.AgentsAmount,
+		cfg.MinChanceToCrash,
+		config.ArrivalModel(cfg),
+		config.ArrivalRates(cfg),
 		dispatchSystem,
+		clockSystem,
+		random.NewGenerator(cfg.Seed, random.AgentsStream),
+		random.NewGenerator(cfg.Seed, random.SeverityStream),
 		metricsSystem,
 		logger,
 	)
 	respondersSystem := responders.NewRespondersSystem(
-		respondersAmount,
-		chanceToHandle,
+		cfg.RespondersAmount,
+		config.RespondersPriorities(cfg),
+		cfg.MinChanceToHandle,
+		config.ServiceLaw(cfg),
+		config.ServiceParameters(cfg),
 		dispatchSystem,
+		clockSystem,
+		random.NewGenerator(cfg.Seed, random.RespondersStream),
 		metricsSystem,
 		logger,
 	)
+	warmupSystem := warmup.NewWarmupSystem(
+		cfg.WarmupTicks,
+		cfg.WarmupTime,
+		config.WarmupDetector(cfg),
+		clockSystem,
+		logger,
+	)
+	timeSeriesSystem := timeseries.NewTimeSeriesSystem(
+		cfg.TimeSeriesCapacity,
+		metricsSystem,
+	)
 
+	Clock = clockSystem
 	Buffer = bufferSystem
 	Pool = poolSystem
 	CommandsSystem = commandsSystem
@@ -83,12 +143,28 @@ func Init(
 	AgentsSystem = agentsSystem
 	RespondersSystem = respondersSystem
 	MetricsSystem = metricsSystem
+	EventsSystem = eventsSystem
+	JobsSystem = jobsSystem
+	WarmupSystem = warmupSystem
+	TimeSeries = timeSeriesSystem
 
-	Logbuffer = logbuffer
+	LogHistory = logHistory
+	Config = cfg
+	resetParameters(cfg)
+	Logger = logging.NewChildLogger(logger, func(event *logging.Event) {
+		logfmt.String(event, "from", "simulation")
+	})
 
-	MsPerUpdate = msPerUpdate
+	Seed = cfg.Seed
+	CurrentEngine = config.Engine(cfg)
+	MsPerUpdate = cfg.MsPerUpdate
+	SpeedIndex = defaultSpeedIndex
 	IsPaused = true
 	TickCounter = 0
+
+	if CurrentEngine == events.EventEngine {
+		scheduleInitialEvents()
+	}
 }
 
 func RunEventLoop() {
@@ -101,15 +177,100 @@ func RunEventLoop() {
 		lag += elapsed
 
 		commands.ProcessCommandsSystem(CommandsSystem)
-		for lag >= MsPerUpdate {
+		for lag >= SecondsPerTick() {
 			if !IsPaused {
-				agents.ProcessAgentSystem(AgentsSystem)
-				responders.ProcessRespondersSystem(RespondersSystem)
-				framebuffer.Next(Logbuffer)
-				TickCounter++
+				Tick()
 			}
 
-			lag -= MsPerUpdate
+			lag -= SecondsPerTick()
 		}
+
+		ptime.SleepInSeconds(min(SecondsPerTick()-lag, commandsPollInterval))
+	}
+}
+
+func Speed() float64 {
+	return SpeedLevels[SpeedIndex]
+}
+
+// SecondsPerTick is the real time between ticks of the event loop.
+func SecondsPerTick() float64 {
+	return MsPerUpdate / Speed()
+}
+
+func SpeedUp() {
+	SpeedIndex = min(SpeedIndex+1, len(SpeedLevels)-1)
+}
+
+func SlowDown() {
+	SpeedIndex = max(SpeedIndex-1, 0)
+}
+
+// Step advances exactly one tick and only while paused.
+func Step() {
+	if !IsPaused {
+		return
 	}
+
+	Tick()
+}
+
+func RunTicks(ticksAmount uint64, shouldStop func() bool) {
+	for range ticksAmount {
+		if shouldStop != nil && shouldStop() {
+			return
+		}
+
+		Tick()
+	}
+}
+
+// Lines logged while a tick runs belong to it, so the log history moves to
+// the tick before any system runs.
+func Tick() {
+	loghistory.SetTick(LogHistory, TickCounter+1)
+	if CurrentEngine == events.EventEngine {
+		processEventsUntilTick()
+
+		return
+	}
+
+	clock.Advance(Clock, MsPerUpdate)
+	expireOverdueJobs()
+	agents.ProcessAgentSystem(AgentsSystem)
+	responders.ProcessRespondersSystem(RespondersSystem)
+	finishTick()
+}
+
+// The tick engine finds expired jobs by polling, the event engine schedules
+// a timeout for each queued job.
+func expireOverdueJobs() {
+	if Pool.Timeout == 0 {
+		return
+	}
+
+	overdue := pools.WaitingUntil(Pool, clock.Now(Clock)-Pool.Timeout)
+	dispatchers.ExpireJobs(DispatchSystem, overdue...)
+}
+
+func finishTick() {
+	TickCounter++
+	observeWarmup()
+	timeseries.Record(TimeSeries, TickCounter)
+}
+
+func observeWarmup() {
+	observation := float64(Pool.Queue.Length)
+	if warmup.Observe(WarmupSystem, TickCounter, observation) {
+		resetStatistics()
+	}
+}
+
+func resetStatistics() {
+	metrics.ResetMetrics(MetricsSystem)
+	agents.ResetStatistics(AgentsSystem)
+	buffer.ResetStatistics(Buffer)
+	pools.ResetStatistics(Pool)
+	responders.ResetStatistics(RespondersSystem)
+	jobs.ResetStatistics(JobsSystem)
 }
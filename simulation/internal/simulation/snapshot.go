@@ -0,0 +1,170 @@
+package simulation
+
+import (
+	"StantStantov/ASS/internal/config"
+	"StantStantov/ASS/internal/simulation/agents"
+	"StantStantov/ASS/internal/simulation/buffer"
+	"StantStantov/ASS/internal/simulation/clock"
+	"StantStantov/ASS/internal/simulation/events"
+	"StantStantov/ASS/internal/simulation/jobs"
+	"StantStantov/ASS/internal/simulation/loghistory"
+	"StantStantov/ASS/internal/simulation/metrics"
+	"StantStantov/ASS/internal/simulation/pools"
+	"StantStantov/ASS/internal/simulation/responders"
+	"StantStantov/ASS/internal/simulation/timeseries"
+	"StantStantov/ASS/internal/simulation/warmup"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/StantStantov/rps/swamp/logging"
+	"github.com/StantStantov/rps/swamp/logging/logfmt"
+)
+
+const SnapshotVersion = 2
+
+type Snapshot struct {
+	Version     uint64         `json:"version"`
+	Config      *config.Config `json:"config"`
+	TickCounter uint64         `json:"tick_counter"`
+	Time        float64        `json:"time"`
+
+	Agents     agents.Snapshot     `json:"agents"`
+	Buffer     buffer.Snapshot     `json:"buffer"`
+	Pool       pools.Snapshot      `json:"pool"`
+	Responders responders.Snapshot `json:"responders"`
+	Jobs       jobs.Snapshot       `json:"jobs"`
+	Metrics    metrics.Snapshot    `json:"metrics"`
+	Events     events.Snapshot     `json:"events"`
+	Warmup     warmup.Snapshot     `json:"warmup"`
+	TimeSeries timeseries.Snapshot `json:"time_series"`
+}
+
+// TakeSnapshot must run between ticks, on the goroutine that runs them.
+func TakeSnapshot() Snapshot {
+	return Snapshot{
+		Version:     SnapshotVersion,
+		Config:      Config,
+		TickCounter: TickCounter,
+		Time:        clock.Now(Clock),
+
+		Agents:     agents.TakeSnapshot(AgentsSystem),
+		Buffer:     buffer.TakeSnapshot(Buffer),
+		Pool:       pools.TakeSnapshot(Pool),
+		Responders: responders.TakeSnapshot(RespondersSystem),
+		Jobs:       jobs.TakeSnapshot(JobsSystem),
+		Metrics:    metrics.TakeSnapshot(MetricsSystem),
+		Events:     events.TakeSnapshot(EventsSystem),
+		Warmup:     warmup.TakeSnapshot(WarmupSystem),
+		TimeSeries: timeseries.TakeSnapshot(TimeSeries),
+	}
+}
+
+// RestoreSnapshot expects the systems to be initialised with the config
+// of the snapshot.
+func RestoreSnapshot(saved Snapshot) {
+	TickCounter = saved.TickCounter
+	loghistory.SetTick(LogHistory, TickCounter)
+	clock.SetTo(Clock, saved.Time)
+
+	agents.RestoreSnapshot(AgentsSystem, saved.Agents)
+	buffer.RestoreSnapshot(Buffer, saved.Buffer)
+	pools.RestoreSnapshot(Pool, saved.Pool)
+	responders.RestoreSnapshot(RespondersSystem, saved.Responders)
+	jobs.RestoreSnapshot(JobsSystem, saved.Jobs)
+	metrics.RestoreSnapshot(MetricsSystem, saved.Metrics)
+	events.RestoreSnapshot(EventsSystem, saved.Events)
+	warmup.RestoreSnapshot(WarmupSystem, saved.Warmup)
+	timeseries.RestoreSnapshot(TimeSeries, saved.TimeSeries)
+
+	logging.GetThenSendInfo(
+		Logger,
+		"restored simulation from snapshot",
+		func(event *logging.Event, level logging.Level) error {
+			logfmt.Unsigned(event, "simulation.tick", TickCounter)
+			logfmt.Floats64(event, "simulation.time", saved.Time)
+
+			return nil
+		},
+	)
+}
+
+func SaveSnapshot(path string) error {
+	data, err := json.Marshal(TakeSnapshot())
+	if err == nil && path == "" {
+		err = errors.New("no snapshot path is set")
+	}
+	if err == nil {
+		err = os.WriteFile(path, data, 0o644)
+	}
+	if err != nil {
+		err = fmt.Errorf("save snapshot %q: %w", path, err)
+		logging.GetThenSendError(
+			Logger,
+			"failed to save snapshot",
+			func(event *logging.Event, level logging.Level) error {
+				logfmt.String(event, "snapshot.error", err.Error())
+
+				return nil
+			},
+		)
+
+		return err
+	}
+
+	logging.GetThenSendInfo(
+		Logger,
+		"saved snapshot",
+		func(event *logging.Event, level logging.Level) error {
+			logfmt.String(event, "snapshot.path", path)
+			logfmt.Unsigned(event, "simulation.tick", TickCounter)
+
+			return nil
+		},
+	)
+
+	return nil
+}
+
+func LoadSnapshot(path string) (Snapshot, error) {
+	saved := Snapshot{}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return saved, fmt.Errorf("read snapshot %q: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return saved, fmt.Errorf("parse snapshot %q: %w", path, err)
+	}
+	if saved.Version != SnapshotVersion {
+		return saved, fmt.Errorf("snapshot %q has version %d, expected %d", path, saved.Version, SnapshotVersion)
+	}
+	if saved.Config == nil {
+		return saved, fmt.Errorf("snapshot %q has no config", path)
+	}
+	if err := config.Validate(saved.Config); err != nil {
+		return saved, fmt.Errorf("snapshot %q: %w", path, err)
+	}
+	if err := validateSnapshot(saved); err != nil {
+		return saved, fmt.Errorf("snapshot %q: %w", path, err)
+	}
+
+	return saved, nil
+}
+
+// validateSnapshot checks the saved systems against each other and against
+// the saved config, so RestoreSnapshot does not have to fail halfway.
+func validateSnapshot(saved Snapshot) error {
+	cfg := saved.Config
+
+	return errors.Join(
+		agents.ValidateSnapshot(saved.Agents),
+		buffer.ValidateSnapshot(saved.Buffer, cfg.AgentsAmount),
+		pools.ValidateSnapshot(saved.Pool, cfg.AgentsAmount),
+		responders.ValidateSnapshot(saved.Responders),
+		jobs.ValidateSnapshot(saved.Jobs, cfg.AgentsAmount),
+		metrics.ValidateSnapshot(saved.Metrics),
+		timeseries.ValidateSnapshot(saved.TimeSeries, cfg.TimeSeriesCapacity),
+	)
+}
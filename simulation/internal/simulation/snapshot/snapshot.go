@@ -0,0 +1,88 @@
+package snapshot
+
+import (
+	"StantStantov/ASS/internal/common/collections"
+	"fmt"
+
+	"github.com/StantStantov/rps/swamp/bools"
+	"github.com/StantStantov/rps/swamp/collections/sparsemap"
+	"github.com/StantStantov/rps/swamp/collections/sparseset"
+)
+
+// Entries keep the dense order of a sparse map, so iterating a restored map
+// visits keys in the same order as the saved one.
+type Entries[V any] struct {
+	Keys   []uint64 `json:"keys"`
+	Values []V      `json:"values"`
+}
+
+func FromSparseMap[V any](sparseMap *sparsemap.SparseMap[uint64, V]) Entries[V] {
+	length := sparsemap.Length(sparseMap)
+	keys := make([]uint64, length)
+	values := make([]V, length)
+	keys, values = sparsemap.GetAllFromSparseMap(sparseMap, keys, values)
+
+	return Entries[V]{Keys: keys, Values: values}
+}
+
+func IntoSparseMap[V any](sparseMap *sparsemap.SparseMap[uint64, V], entries Entries[V]) {
+	if len(entries.Keys) != len(entries.Values) {
+		panic(fmt.Sprintf("Restore Sparse Map %v %v", entries.Keys, len(entries.Values)))
+	}
+
+	collections.ClearSparseMap(sparseMap)
+
+	added := make([]bool, len(entries.Keys))
+	added = sparsemap.AddIntoSparseMap(sparseMap, added, entries.Keys, entries.Values)
+	if bools.AnyFalse(added...) {
+		panic(fmt.Sprintf("Restore Sparse Map %v %v", entries.Keys, added))
+	}
+}
+
+// ValidateEntries reports entries IntoSparseMap can not restore into a sparse
+// map of capacity.
+func ValidateEntries[V any](name string, entries Entries[V], capacity uint64) error {
+	if len(entries.Keys) != len(entries.Values) {
+		return fmt.Errorf("%s has %d keys and %d values", name, len(entries.Keys), len(entries.Values))
+	}
+
+	return ValidateKeys(name, entries.Keys, capacity)
+}
+
+// ValidateKeys reports keys that are out of capacity or repeated, a sparse
+// map or a sparse set rejects both.
+func ValidateKeys(name string, keys []uint64, capacity uint64) error {
+	seen := make(map[uint64]bool, len(keys))
+	for _, key := range keys {
+		if key >= capacity {
+			return fmt.Errorf("%s has key %d out of capacity %d", name, key, capacity)
+		}
+		if seen[key] {
+			return fmt.Errorf("%s has key %d twice", name, key)
+		}
+		seen[key] = true
+	}
+
+	return nil
+}
+
+func FromSparseSet(sparseSet *sparseset.SparseSet[uint64]) []uint64 {
+	values := make([]uint64, sparseset.Length(sparseSet))
+
+	return sparseset.GetAllFromSparseSet(sparseSet, values)
+}
+
+func IntoSparseSet(sparseSet *sparseset.SparseSet[uint64], values []uint64) {
+	oldValues := FromSparseSet(sparseSet)
+	removed := make([]bool, len(oldValues))
+	removed = sparseset.RemoveFromSparseSet(sparseSet, removed, oldValues...)
+	if bools.AnyFalse(removed...) {
+		panic(fmt.Sprintf("Clear Sparse Set %v %v", oldValues, removed))
+	}
+
+	added := make([]bool, len(values))
+	added = sparseset.AddIntoSparseSet(sparseSet, added, values...)
+	if bools.AnyFalse(added...) {
+		panic(fmt.Sprintf("Restore Sparse Set %v %v", values, added))
+	}
+}
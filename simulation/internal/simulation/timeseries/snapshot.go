@@ -0,0 +1,87 @@
+package timeseries
+
+import (
+	"StantStantov/ASS/internal/simulation/metrics"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Only values and sums of the previous metrics are kept, they are all
+// Record needs to compute deltas.
+type Snapshot struct {
+	Samples [][]float64 `json:"samples"`
+	Ticks   []uint64    `json:"ticks"`
+	Head    uint64      `json:"head"`
+	Length  uint64      `json:"length"`
+	Values  []uint64    `json:"values"`
+	Sums    []float64   `json:"sums"`
+}
+
+func TakeSnapshot(system *TimeSeriesSystem) Snapshot {
+	system.Mutex.Lock()
+	defer system.Mutex.Unlock()
+
+	saved := Snapshot{}
+
+	saved.Samples = make([][]float64, len(system.Samples))
+	for i, samples := range system.Samples {
+		saved.Samples[i] = slices.Clone(samples)
+	}
+	saved.Ticks = slices.Clone(system.Ticks)
+	saved.Head = system.Head
+	saved.Length = system.Length
+
+	saved.Values = make([]uint64, len(system.Previous))
+	saved.Sums = make([]float64, len(system.Previous))
+	for i, metric := range system.Previous {
+		saved.Values[i] = metric.Value
+		saved.Sums[i] = metric.Sum
+	}
+
+	return saved
+}
+
+// ValidateSnapshot reports what RestoreSnapshot can not restore into time
+// series of capacity ticks.
+func ValidateSnapshot(saved Snapshot, capacity uint64) error {
+	errs := []error{}
+
+	metricsAmount := len(metrics.MetricTypesNames) + len(metrics.GaugeTypesNames) + len(metrics.HistogramTypesNames)
+	if len(saved.Samples) != len(SeriesTypesNames)+metricsAmount {
+		errs = append(errs, fmt.Errorf("time series have %d samples, expected %d", len(saved.Samples), len(SeriesTypesNames)+metricsAmount))
+	}
+	for i, samples := range saved.Samples {
+		if uint64(len(samples)) != capacity {
+			errs = append(errs, fmt.Errorf("time series samples %d have %d values, expected %d", i, len(samples), capacity))
+		}
+	}
+	if uint64(len(saved.Ticks)) != capacity {
+		errs = append(errs, fmt.Errorf("time series have %d ticks, expected %d", len(saved.Ticks), capacity))
+	}
+	if saved.Length > capacity || (capacity != 0 && saved.Head >= capacity) {
+		errs = append(errs, fmt.Errorf("time series have head %d and length %d, capacity is %d", saved.Head, saved.Length, capacity))
+	}
+	if len(saved.Values) != metricsAmount || len(saved.Sums) != metricsAmount {
+		errs = append(errs, fmt.Errorf("time series have %d values and %d sums, expected %d", len(saved.Values), len(saved.Sums), metricsAmount))
+	}
+
+	return errors.Join(errs...)
+}
+
+func RestoreSnapshot(system *TimeSeriesSystem, saved Snapshot) {
+	system.Mutex.Lock()
+	defer system.Mutex.Unlock()
+
+	for i, samples := range saved.Samples {
+		copy(system.Samples[i], samples)
+	}
+	copy(system.Ticks, saved.Ticks)
+	system.Head = saved.Head
+	system.Length = saved.Length
+
+	for i := range system.Previous {
+		system.Previous[i].Value = saved.Values[i]
+		system.Previous[i].Sum = saved.Sums[i]
+	}
+}
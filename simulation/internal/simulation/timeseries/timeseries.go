@@ -0,0 +1,140 @@
+package timeseries
+
+import (
+	"StantStantov/ASS/internal/simulation/metrics"
+	"slices"
+	"sync"
+)
+
+type SeriesType uint8
+
+const (
+	AlertsPerTickSeries SeriesType = iota
+	RewriteRateSeries
+)
+
+var SeriesTypesNames = []string{
+	"alerts_per_tick",
+	"rewrite_rate",
+}
+
+type TimeSeriesSystem struct {
+	Names    []string
+	Samples  [][]float64
+	Ticks    []uint64
+	Head     uint64
+	Length   uint64
+	Capacity uint64
+
+	Previous []metrics.Metric
+
+	Mutex *sync.Mutex
+
+	Metrics *metrics.MetricsSystem
+}
+
+func NewTimeSeriesSystem(
+	capacity uint64,
+	metricsSystem *metrics.MetricsSystem,
+) *TimeSeriesSystem {
+	system := &TimeSeriesSystem{}
+
+	system.Previous = make([]metrics.Metric, metrics.MetricsAmount(metricsSystem))
+	system.Previous = metrics.GetMetrics(metricsSystem, system.Previous)
+
+	system.Names = slices.Clone(SeriesTypesNames)
+	for _, metric := range system.Previous {
+		system.Names = append(system.Names, metric.Name)
+	}
+	system.Samples = make([][]float64, len(system.Names))
+	for i := range system.Samples {
+		system.Samples[i] = make([]float64, capacity)
+	}
+	system.Ticks = make([]uint64, capacity)
+	system.Head = 0
+	system.Length = 0
+	system.Capacity = capacity
+
+	system.Mutex = &sync.Mutex{}
+
+	system.Metrics = metricsSystem
+
+	return system
+}
+
+// Record samples counters and histograms as their change since the previous
+// tick and gauges as their current value.
+func Record(system *TimeSeriesSystem, tick uint64) {
+	if system.Capacity == 0 {
+		return
+	}
+
+	current := make([]metrics.Metric, len(system.Previous))
+	current = metrics.GetMetrics(system.Metrics, current)
+
+	values := make([]float64, len(system.Names))
+	for i, metric := range current {
+		value := float64(metric.Value)
+		if metric.Kind != metrics.GaugeMetric {
+			value = delta(system.Previous[i].Value, metric.Value)
+		}
+		values[len(SeriesTypesNames)+i] = value
+	}
+
+	batchSizesIndex := metrics.HistogramIndex(system.Metrics, metrics.BatchSizeHistogram)
+	batchSizes := current[batchSizesIndex]
+	previousBatchSizes := system.Previous[batchSizesIndex]
+	alertsArrived := batchSizes.Sum - previousBatchSizes.Sum
+	if alertsArrived < 0 {
+		alertsArrived = batchSizes.Sum
+	}
+	values[AlertsPerTickSeries] = alertsArrived
+	if alertsArrived != 0 {
+		rewritten := values[len(SeriesTypesNames)+int(metrics.AlertsRewrittenCounter)]
+		values[RewriteRateSeries] = rewritten / alertsArrived
+	}
+	system.Previous = current
+
+	system.Mutex.Lock()
+	defer system.Mutex.Unlock()
+
+	index := (system.Head + system.Length) % system.Capacity
+	if system.Length == system.Capacity {
+		system.Head = (system.Head + 1) % system.Capacity
+	} else {
+		system.Length++
+	}
+	for i, value := range values {
+		system.Samples[i][index] = value
+	}
+	system.Ticks[index] = tick
+}
+
+func SeriesIndex(system *TimeSeriesSystem, name string) (int, bool) {
+	index := slices.Index(system.Names, name)
+
+	return index, index >= 0
+}
+
+// Window returns up to the last amount samples of a series, oldest first.
+func Window(system *TimeSeriesSystem, series int, amount uint64) []float64 {
+	system.Mutex.Lock()
+	defer system.Mutex.Unlock()
+
+	amount = min(amount, system.Length)
+	window := make([]float64, amount)
+	start := system.Head + system.Length - amount
+	for i := range amount {
+		window[i] = system.Samples[series][(start+i)%system.Capacity]
+	}
+
+	return window
+}
+
+func delta(previous uint64, current uint64) float64 {
+	if current < previous {
+		return float64(current)
+	}
+
+	return float64(current - previous)
+}
@@ -0,0 +1,32 @@
+package warmup
+
+import "slices"
+
+type Snapshot struct {
+	Observations []float64 `json:"observations"`
+	Batches      []float64 `json:"batches"`
+
+	IsOver      bool    `json:"is_over"`
+	EndedAtTick uint64  `json:"ended_at_tick"`
+	EndedAt     float64 `json:"ended_at"`
+}
+
+func TakeSnapshot(system *WarmupSystem) Snapshot {
+	return Snapshot{
+		Observations: slices.Clone(system.Observations),
+		Batches:      slices.Clone(system.Batches),
+
+		IsOver:      system.IsOver,
+		EndedAtTick: system.EndedAtTick,
+		EndedAt:     system.EndedAt,
+	}
+}
+
+func RestoreSnapshot(system *WarmupSystem, saved Snapshot) {
+	system.Observations = append([]float64{}, saved.Observations...)
+	system.Batches = append([]float64{}, saved.Batches...)
+
+	system.IsOver = saved.IsOver
+	system.EndedAtTick = saved.EndedAtTick
+	system.EndedAt = saved.EndedAt
+}
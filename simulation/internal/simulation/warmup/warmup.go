@@ -0,0 +1,188 @@
+package warmup
+
+import (
+	"StantStantov/ASS/internal/simulation/clock"
+	"slices"
+
+	"github.com/StantStantov/rps/swamp/logging"
+	"github.com/StantStantov/rps/swamp/logging/logfmt"
+)
+
+type Detector uint8
+
+const (
+	NoDetector Detector = iota
+	MserDetector
+)
+
+const (
+	mserBatchSize  = 5
+	mserMinBatches = 20
+	mserMinTail    = 5
+)
+
+var DetectorsNames = []string{
+	"none",
+	"mser",
+}
+
+func DetectorFromName(name string) (Detector, bool) {
+	index := slices.Index(DetectorsNames, name)
+	if index < 0 {
+		return NoDetector, false
+	}
+
+	return Detector(index), true
+}
+
+type WarmupSystem struct {
+	Ticks    uint64
+	Time     float64
+	Detector Detector
+
+	Observations []float64
+	Batches      []float64
+
+	IsOver      bool
+	EndedAtTick uint64
+	EndedAt     float64
+
+	Clock  *clock.ClockSystem
+	Logger *logging.Logger
+}
+
+func NewWarmupSystem(
+	ticks uint64,
+	time float64,
+	detector Detector,
+	clockSystem *clock.ClockSystem,
+	logger *logging.Logger,
+) *WarmupSystem {
+	system := &WarmupSystem{}
+
+	system.Ticks = ticks
+	system.Time = time
+	system.Detector = detector
+
+	system.Observations = []float64{}
+	system.Batches = []float64{}
+
+	system.IsOver = ticks == 0 && time == 0 && detector == NoDetector
+	system.EndedAtTick = 0
+	system.EndedAt = clock.Now(clockSystem)
+
+	system.Clock = clockSystem
+	system.Logger = logging.NewChildLogger(logger, func(event *logging.Event) {
+		logfmt.String(event, "from", "warmup_system")
+	})
+
+	return system
+}
+
+// Observe takes one observation per tick and reports whether the warm-up
+// ends on it. Statistics can only be reset from the current tick on, so the
+// warm-up always ends on the tick it is detected at.
+func Observe(system *WarmupSystem, tick uint64, observation float64) bool {
+	if system.IsOver {
+		return false
+	}
+
+	now := clock.Now(system.Clock)
+	isOver := false
+	switch {
+	case system.Ticks != 0:
+		isOver = tick >= system.Ticks
+	case system.Time != 0:
+		isOver = now >= system.Time
+	case system.Detector == MserDetector:
+		isOver = observeMser(system, observation)
+	}
+	if !isOver {
+		return false
+	}
+
+	system.IsOver = true
+	system.EndedAtTick = tick
+	system.EndedAt = now
+
+	logging.GetThenSendInfo(
+		system.Logger,
+		"warm-up is over, statistics are reset",
+		func(event *logging.Event, level logging.Level) error {
+			logfmt.Unsigned(event, "warmup.tick", tick)
+			logfmt.Floats64(event, "warmup.time", now)
+
+			return nil
+		},
+	)
+
+	return true
+}
+
+// The process is steady once the batches MSER truncates are at most half
+// of all batches.
+func observeMser(system *WarmupSystem, observation float64) bool {
+	system.Observations = append(system.Observations, observation)
+	if len(system.Observations) < mserBatchSize {
+		return false
+	}
+
+	sum := 0.0
+	for _, value := range system.Observations {
+		sum += value
+	}
+	system.Batches = append(system.Batches, sum/mserBatchSize)
+	system.Observations = system.Observations[:0]
+	if len(system.Batches) < mserMinBatches {
+		return false
+	}
+
+	truncation := MserTruncation(system.Batches)
+	if truncation > len(system.Batches)/2 {
+		return false
+	}
+
+	logging.GetThenSendInfo(
+		system.Logger,
+		"mser detected steady state",
+		func(event *logging.Event, level logging.Level) error {
+			logfmt.Integer(event, "warmup.truncation", truncation)
+			logfmt.Integer(event, "warmup.batches", len(system.Batches))
+			logfmt.Integer(event, "warmup.batch_size", mserBatchSize)
+
+			return nil
+		},
+	)
+
+	return true
+}
+
+// MserTruncation returns the amount of leading batches whose removal
+// minimises the squared standard error of the remaining mean.
+func MserTruncation(batches []float64) int {
+	amount := len(batches)
+	lastTruncation := max(amount-mserMinTail, 0)
+
+	sum := 0.0
+	squares := 0.0
+	bestTruncation := lastTruncation
+	bestStatistic := -1.0
+	for truncation := amount - 1; truncation >= 0; truncation-- {
+		value := batches[truncation]
+		sum += value
+		squares += value * value
+		if truncation > lastTruncation {
+			continue
+		}
+
+		remaining := float64(amount - truncation)
+		mean := sum / remaining
+		statistic := (squares - remaining*mean*mean) / (remaining * remaining)
+		if bestStatistic < 0 || statistic <= bestStatistic {
+			bestStatistic = statistic
+			bestTruncation = truncation
+		}
+	}
+
+	return bestTruncation
+}
@@ -0,0 +1,82 @@
+package sweep
+
+import (
+	"StantStantov/ASS/internal/config"
+	"StantStantov/ASS/internal/report"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+func RunReplications(executable string, cfg *config.Config) (report.Estimates, error) {
+	configPath, err := writeChildConfig(cfg)
+	if err != nil {
+		return report.Estimates{}, err
+	}
+	defer os.Remove(configPath)
+
+	amount := cfg.Replications
+	runs := []Run{}
+	estimates := report.Estimates{}
+	for {
+		from := len(runs)
+		runs = append(runs, make([]Run, int(amount)-from)...)
+		runParallel(cfg.Workers, from, len(runs), func(index int) {
+			runs[index] = runReplication(executable, configPath, cfg.Seed+uint64(index))
+		})
+
+		summaries := make([]report.Summary, 0, len(runs))
+		for _, run := range runs {
+			if run.Err == nil {
+				summaries = append(summaries, run.Summary)
+			}
+		}
+		if len(summaries) < 2 {
+			break
+		}
+		estimates = report.EstimateSummaries(summaries, cfg.Confidence)
+
+		if cfg.TargetHalfWidth == 0 || amount >= cfg.MaxReplications || areAllPrecise(estimates, cfg.TargetHalfWidth) {
+			break
+		}
+		amount = min(amount+cfg.Workers, cfg.MaxReplications)
+	}
+
+	errs := []error{}
+	for _, run := range runs {
+		if run.Err != nil {
+			errs = append(errs, run.Err)
+		}
+	}
+	if estimates.Replications < 2 {
+		errs = append(errs, errors.New("replications need at least 2 successful runs to estimate intervals"))
+	}
+
+	return estimates, errors.Join(errs...)
+}
+
+func areAllPrecise(estimates report.Estimates, relativeHalfWidth float64) bool {
+	for _, estimate := range estimates.Statistics {
+		if !report.IsPrecise(estimate, relativeHalfWidth) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func runReplication(executable string, configPath string, seed uint64) Run {
+	seedText := strconv.FormatUint(seed, 10)
+	run := Run{Values: []string{seedText}}
+
+	summary, err := runChild(executable, []string{"-config", configPath, "-seed=" + seedText})
+	if err != nil {
+		run.Err = fmt.Errorf("replication with seed %s: %w", seedText, err)
+
+		return run
+	}
+	run.Summary = summary
+
+	return run
+}
@@ -0,0 +1,240 @@
+package sweep
+
+import (
+	"StantStantov/ASS/internal/config"
+	"StantStantov/ASS/internal/report"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type Range struct {
+	Name   string
+	Values []string
+}
+
+type Run struct {
+	Values  []string
+	Summary report.Summary
+	Err     error
+}
+
+var SummaryColumns = []string{
+	"seed",
+	"rewrite_percentage",
+	"duplicate_percentage",
+	"load_percentage",
+	"time_in_system",
+	"jobs_finished",
+}
+
+func ParseRange(text string) (Range, error) {
+	name, valuesText, ok := strings.Cut(text, "=")
+	name = strings.TrimPrefix(strings.TrimSpace(name), "-")
+	if !ok || name == "" || valuesText == "" {
+		return Range{}, fmt.Errorf("sweep range %q must look like name=a,b,c or name=start:stop:step", text)
+	}
+
+	bounds := strings.Split(valuesText, ":")
+	if len(bounds) != 3 {
+		values := strings.Split(valuesText, ",")
+		for i := range values {
+			values[i] = strings.TrimSpace(values[i])
+		}
+
+		return Range{Name: name, Values: values}, nil
+	}
+
+	numbers := make([]float64, len(bounds))
+	for i, bound := range bounds {
+		number, err := strconv.ParseFloat(strings.TrimSpace(bound), 64)
+		if err != nil {
+			return Range{}, fmt.Errorf("sweep range %q: %w", text, err)
+		}
+		numbers[i] = number
+	}
+
+	start, stop, step := numbers[0], numbers[1], numbers[2]
+	if !(step > 0) || stop < start {
+		return Range{}, fmt.Errorf("sweep range %q must have start <= stop and a positive step", text)
+	}
+
+	amount := int(math.Floor((stop-start)/step+1e-9)) + 1
+	values := make([]string, amount)
+	for i := range values {
+		value := math.Round((start+float64(i)*step)*1e9) / 1e9
+		values[i] = strconv.FormatFloat(value, 'f', -1, 64)
+	}
+
+	return Range{Name: name, Values: values}, nil
+}
+
+func Grid(ranges []Range) [][]string {
+	points := [][]string{{}}
+	for _, valuesRange := range ranges {
+		nextPoints := make([][]string, 0, len(points)*len(valuesRange.Values))
+		for _, point := range points {
+			for _, value := range valuesRange.Values {
+				nextPoint := append(append([]string{}, point...), value)
+				nextPoints = append(nextPoints, nextPoint)
+			}
+		}
+		points = nextPoints
+	}
+
+	return points
+}
+
+func RunSweep(executable string, cfg *config.Config, ranges []Range, workers uint64) ([]Run, error) {
+	configPath, err := writeChildConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(configPath)
+
+	points := Grid(ranges)
+	runs := make([]Run, len(points))
+	runParallel(workers, 0, len(points), func(index int) {
+		runs[index] = runPoint(executable, configPath, ranges, points[index])
+	})
+
+	errs := []error{}
+	for _, run := range runs {
+		if run.Err != nil {
+			errs = append(errs, run.Err)
+		}
+	}
+
+	return runs, errors.Join(errs...)
+}
+
+func WriteCSV(output io.Writer, ranges []Range, runs []Run) error {
+	writer := csv.NewWriter(output)
+
+	header := make([]string, 0, len(ranges)+len(SummaryColumns))
+	for _, valuesRange := range ranges {
+		header = append(header, valuesRange.Name)
+	}
+	header = append(header, SummaryColumns...)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, run := range runs {
+		if run.Err != nil {
+			continue
+		}
+
+		summary := run.Summary
+		row := append([]string{}, run.Values...)
+		row = append(row,
+			strconv.FormatUint(summary.Seed, 10),
+			strconv.FormatFloat(summary.RewritePercentage, 'f', -1, 64),
+			strconv.FormatFloat(summary.DuplicatePercentage, 'f', -1, 64),
+			strconv.FormatFloat(summary.LoadPercentage, 'f', -1, 64),
+			strconv.FormatFloat(summary.TimeInSystem, 'f', -1, 64),
+			strconv.FormatUint(summary.JobsFinished, 10),
+		)
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+
+	return writer.Error()
+}
+
+func writeChildConfig(cfg *config.Config) (string, error) {
+	childConfig := *cfg
+	childConfig.Headless = true
+	childConfig.SummaryFormat = "json"
+	childConfig.OutputPath = ""
+	childConfig.LogPath = os.DevNull
+	childConfig.Sweep = nil
+	childConfig.Replications = 0
+	childConfig.MetricsAddress = ""
+	childConfig.SnapshotPath = ""
+
+	data, err := json.Marshal(&childConfig)
+	if err != nil {
+		return "", err
+	}
+
+	file, err := os.CreateTemp("", "sweep-*.json")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if _, err := file.Write(data); err != nil {
+		os.Remove(file.Name())
+
+		return "", err
+	}
+
+	return file.Name(), nil
+}
+
+func runParallel(workers uint64, from int, to int, run func(index int)) {
+	indexes := make(chan int)
+	waitGroup := &sync.WaitGroup{}
+	for range max(workers, 1) {
+		waitGroup.Go(func() {
+			for index := range indexes {
+				run(index)
+			}
+		})
+	}
+	for i := from; i < to; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	waitGroup.Wait()
+}
+
+func runPoint(executable string, configPath string, ranges []Range, values []string) Run {
+	run := Run{Values: values}
+
+	args := []string{"-config", configPath}
+	for i, valuesRange := range ranges {
+		args = append(args, "-"+valuesRange.Name+"="+values[i])
+	}
+
+	summary, err := runChild(executable, args)
+	if err != nil {
+		run.Err = fmt.Errorf("run %v: %w", values, err)
+
+		return run
+	}
+	run.Summary = summary
+
+	return run
+}
+
+func runChild(executable string, args []string) (report.Summary, error) {
+	export := report.Export{}
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	command := exec.Command(executable, args...)
+	command.Stdout = stdout
+	command.Stderr = stderr
+	if err := command.Run(); err != nil {
+		return export.Summary, fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	if err := json.Unmarshal(stdout.Bytes(), &export); err != nil {
+		return export.Summary, fmt.Errorf("parse summary: %w", err)
+	}
+
+	return export.Summary, nil
+}
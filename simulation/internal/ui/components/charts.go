@@ -0,0 +1,130 @@
+package components
+
+import (
+	"StantStantov/ASS/internal/simulation"
+	"StantStantov/ASS/internal/simulation/metrics"
+	"StantStantov/ASS/internal/simulation/timeseries"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const chartHeight = 3
+
+var ChartWindows = []uint64{100, 250, 500, 1000}
+
+var ChartsSeries = []string{
+	timeseries.SeriesTypesNames[timeseries.AlertsPerTickSeries],
+	metrics.GaugeTypesNames[metrics.JobsWaitingGauge],
+	metrics.GaugeTypesNames[metrics.RespondersBusyGauge],
+	timeseries.SeriesTypesNames[timeseries.RewriteRateSeries],
+}
+
+var sparkLevels = []rune(" ▁▂▃▄▅▆▇█")
+
+func ChartsWindowHeight() int {
+	return len(ChartsSeries) * (1 + chartHeight)
+}
+
+type ChartsWindow struct {
+	Buffer      *strings.Builder
+	WindowIndex int
+
+	viewport.Model
+}
+
+func NextChartWindow(cw ChartsWindow) ChartsWindow {
+	cw.WindowIndex = (cw.WindowIndex + 1) % len(ChartWindows)
+
+	return cw
+}
+
+func (cw ChartsWindow) Init() tea.Cmd {
+	return nil
+}
+
+func (cw ChartsWindow) Update(tea.Msg) (tea.Model, tea.Cmd) {
+	return cw, nil
+}
+
+func (cw ChartsWindow) View() string {
+	defer cw.Buffer.Reset()
+
+	window := ChartWindows[cw.WindowIndex]
+	for _, name := range ChartsSeries {
+		series, ok := timeseries.SeriesIndex(simulation.TimeSeries, name)
+		if !ok {
+			continue
+		}
+
+		values := timeseries.Window(simulation.TimeSeries, series, window)
+		last, lowest, highest := 0.0, 0.0, 0.0
+		if len(values) != 0 {
+			last, lowest, highest = values[len(values)-1], slices.Min(values), slices.Max(values)
+		}
+
+		fmt.Fprintf(cw.Buffer, "%s (last %d ticks): now=%.2f min=%.2f max=%.2f\n", name, window, last, lowest, highest)
+		for _, row := range Sparkline(values, cw.Model.Width, chartHeight) {
+			fmt.Fprintf(cw.Buffer, "%s\n", row)
+		}
+	}
+
+	cw.Model.SetContent(strings.TrimSuffix(cw.Buffer.String(), "\n"))
+
+	return cw.Model.View()
+}
+
+// Sparkline draws values as rows of block characters, the newest value
+// on the right. Values above the width are averaged into columns.
+func Sparkline(values []float64, width int, height int) []string {
+	columns := resample(values, width)
+	highest := 0.0
+	for _, value := range columns {
+		highest = max(highest, value)
+	}
+
+	rows := make([]string, height)
+	levelsPerRow := len(sparkLevels) - 1
+	for row := range rows {
+		floor := (height - 1 - row) * levelsPerRow
+		builder := strings.Builder{}
+		builder.WriteString(strings.Repeat(" ", max(width-len(columns), 0)))
+		for _, value := range columns {
+			level := 0
+			if highest > 0 {
+				level = int(value / highest * float64(height*levelsPerRow))
+			}
+			fill := min(max(level-floor, 0), levelsPerRow)
+			builder.WriteRune(sparkLevels[fill])
+		}
+		rows[row] = builder.String()
+	}
+
+	return rows
+}
+
+func resample(values []float64, width int) []float64 {
+	if width <= 0 {
+		return nil
+	}
+	if len(values) <= width {
+		return values
+	}
+
+	columns := make([]float64, width)
+	for i := range columns {
+		from := i * len(values) / width
+		to := max((i+1)*len(values)/width, from+1)
+
+		sum := 0.0
+		for _, value := range values[from:to] {
+			sum += value
+		}
+		columns[i] = sum / float64(to-from)
+	}
+
+	return columns
+}
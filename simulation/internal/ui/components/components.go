@@ -2,9 +2,11 @@ package components
 
 import (
 	"StantStantov/ASS/internal/simulation"
-	"StantStantov/ASS/internal/simulation/framebuffer"
+	"StantStantov/ASS/internal/simulation/clock"
+	"StantStantov/ASS/internal/simulation/jobs"
 	"StantStantov/ASS/internal/simulation/metrics"
 	"StantStantov/ASS/internal/simulation/models"
+	"StantStantov/ASS/internal/ui/controls"
 	"StantStantov/ASS/internal/ui/input"
 	"fmt"
 	"strings"
@@ -23,8 +25,11 @@ var style = lipgloss.NewStyle().BorderStyle(lipgloss.NormalBorder())
 type MainMenu struct {
 	Input *input.InputSystem
 
-	Info InfoWindow
-	Logs LogsWindow
+	Info       InfoWindow
+	Charts     ChartsWindow
+	Logs       LogsWindow
+	Parameters ParametersForm
+	Details    DetailsPanel
 }
 
 func (mainMenu MainMenu) Init() tea.Cmd {
@@ -34,8 +39,64 @@ func (mainMenu MainMenu) Init() tea.Cmd {
 func (mainMenu MainMenu) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if mainMenu.Parameters.Active {
+			parameters, cmd := mainMenu.Parameters.Update(msg)
+			mainMenu.Parameters = parameters.(ParametersForm)
+
+			return mainMenu, tea.Batch(nextFrame, cmd)
+		}
+		if mainMenu.Details.Prompting {
+			details, cmd := mainMenu.Details.Update(msg)
+			mainMenu.Details = details.(DetailsPanel)
+
+			return mainMenu, tea.Batch(nextFrame, cmd)
+		}
+		if mainMenu.Logs.Prompt != NoLogsPrompt {
+			logs, cmd := mainMenu.Logs.Update(msg)
+			mainMenu.Logs = logs.(LogsWindow)
+
+			return mainMenu, tea.Batch(nextFrame, cmd)
+		}
+
 		keyPress := msg.String()
-		input.ProcessKeyPress(mainMenu.Input, keyPress)
+		switch controls.KeyName(keyPress) {
+		case controls.ParametersKey:
+			parameters, cmd := OpenParametersForm(mainMenu.Parameters)
+			mainMenu.Parameters = parameters
+
+			return mainMenu, tea.Batch(nextFrame, cmd)
+		case controls.DetailsKey:
+			details, cmd := OpenDetailsPrompt(mainMenu.Details)
+			mainMenu.Details = details
+
+			return mainMenu, tea.Batch(nextFrame, cmd)
+		case controls.DetailsPreviousKey:
+			mainMenu.Details = StepDetails(mainMenu.Details, -1)
+		case controls.DetailsNextKey:
+			mainMenu.Details = StepDetails(mainMenu.Details, 1)
+		case controls.FormCancelKey:
+			mainMenu.Details.Active = false
+		case controls.ChartWindowKey:
+			mainMenu.Charts = NextChartWindow(mainMenu.Charts)
+		case controls.LogsSearchKey:
+			logs, cmd := OpenLogsPrompt(mainMenu.Logs, FilterLogsPrompt)
+			mainMenu.Logs = logs
+
+			return mainMenu, tea.Batch(nextFrame, cmd)
+		case controls.LogsTickKey:
+			logs, cmd := OpenLogsPrompt(mainMenu.Logs, TickLogsPrompt)
+			mainMenu.Logs = logs
+
+			return mainMenu, tea.Batch(nextFrame, cmd)
+		case controls.LogsFreezeKey:
+			mainMenu.Logs = ToggleLogsFreeze(mainMenu.Logs)
+		case controls.LogsPageUpKey:
+			mainMenu.Logs = ScrollLogs(mainMenu.Logs, -1)
+		case controls.LogsPageDownKey:
+			mainMenu.Logs = ScrollLogs(mainMenu.Logs, 1)
+		default:
+			input.ProcessKeyPress(mainMenu.Input, keyPress)
+		}
 	case tea.WindowSizeMsg:
 		borderWidth := style.GetHorizontalBorderSize()
 		borderHeight := style.GetVerticalBorderSize()
@@ -46,9 +107,13 @@ func (mainMenu MainMenu) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		infoTablesHeight := windowHeight
 		mainMenu.Info = InfoWindow{Buffer: mainMenu.Info.Buffer, Model: viewport.New(infoTablesWidth, infoTablesHeight)}
 
+		chartsWidth := windowWidth - infoTablesWidth
+		chartsHeight := min(ChartsWindowHeight(), windowHeight/2)
+		mainMenu.Charts = ChartsWindow{Buffer: mainMenu.Charts.Buffer, WindowIndex: mainMenu.Charts.WindowIndex, Model: viewport.New(chartsWidth, chartsHeight)}
+
 		logsWidth := windowWidth - infoTablesWidth
-		logsHeight := windowHeight
-		mainMenu.Logs = LogsWindow{Buffer: mainMenu.Logs.Buffer, LogBuffer: mainMenu.Logs.LogBuffer, Model: viewport.New(logsWidth, logsHeight)}
+		logsHeight := windowHeight - chartsHeight - borderHeight
+		mainMenu.Logs.Model = viewport.New(logsWidth, logsHeight-logsHeaderHeight)
 	}
 
 	return mainMenu, nextFrame
@@ -56,39 +121,24 @@ func (mainMenu MainMenu) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 func (mainMenu MainMenu) View() string {
 	infoWindow := mainMenu.Info.View()
+	infoWindowSize := lipgloss.NewStyle().Width(mainMenu.Info.Width).Height(mainMenu.Info.Height).MaxHeight(mainMenu.Info.Height)
+	if mainMenu.Details.Active || mainMenu.Details.Prompting {
+		infoWindow = infoWindowSize.Render(mainMenu.Details.View())
+	}
+	if mainMenu.Parameters.Active {
+		infoWindow = infoWindowSize.Render(mainMenu.Parameters.View())
+	}
 	infoWindowStyled := style.Render(infoWindow)
 
+	chartsWindow := mainMenu.Charts.View()
+	chartsWindowStyled := style.Render(chartsWindow)
+
 	viewport := mainMenu.Logs.View()
 	viewportStyled := style.Render(viewport)
 
-	return lipgloss.JoinHorizontal(lipgloss.Left, infoWindowStyled, viewportStyled)
-}
-
-type LogsWindow struct {
-	Buffer    *strings.Builder
-	LogBuffer *framebuffer.Buffer
-
-	viewport.Model
-}
-
-func (lw LogsWindow) Init() tea.Cmd {
-	return nil
-}
-
-func (lw LogsWindow) Update(tea.Msg) (tea.Model, tea.Cmd) {
-	return lw, nil
-}
-
-func (lw LogsWindow) View() string {
-	defer lw.Buffer.Reset()
-
-	framebuffer.String(lw.LogBuffer, lw.Buffer)
-	logsToRender := lw.Buffer.String()
-	logsToRenderWrapped := lipgloss.NewStyle().Width(lw.Model.Width).Render(logsToRender)
-
-	lw.Model.SetContent(logsToRenderWrapped)
+	rightColumn := lipgloss.JoinVertical(lipgloss.Left, chartsWindowStyled, viewportStyled)
 
-	return lw.Model.View()
+	return lipgloss.JoinHorizontal(lipgloss.Left, infoWindowStyled, rightColumn)
 }
 
 type InfoWindow struct {
@@ -115,6 +165,8 @@ func (iw InfoWindow) View() string {
 	fmt.Fprintf(iw.Buffer, "Simulation:\n")
 	fmt.Fprintf(iw.Buffer, "Status:    %s\n", status)
 	fmt.Fprintf(iw.Buffer, "Tick:      %v\n", simulation.TickCounter)
+	fmt.Fprintf(iw.Buffer, "Time:      %.3fs\n", clock.Now(simulation.Clock))
+	fmt.Fprintf(iw.Buffer, "Speed:     x%g (%.3fs per tick)\n", simulation.Speed(), simulation.SecondsPerTick())
 	fmt.Fprintf(iw.Buffer, "\n")
 
 	fmt.Fprintf(iw.Buffer, "Agents:\n")
@@ -125,11 +177,11 @@ func (iw InfoWindow) View() string {
 
 	jobsBufferedAmount := sparsemap.Length(simulation.Buffer.Values)
 	jobsIds := make([]uint64, jobsBufferedAmount)
-	jobs := make([]buffers.SetBuffer[models.MachineInfo, uint64], jobsBufferedAmount)
-	sparsemap.GetAllFromSparseMap(simulation.Buffer.Values, jobsIds, jobs)
-	jobsAlertsAmounts := make([]uint64, len(jobs))
+	jobsBuffered := make([]buffers.SetBuffer[models.MachineInfo, uint64], jobsBufferedAmount)
+	sparsemap.GetAllFromSparseMap(simulation.Buffer.Values, jobsIds, jobsBuffered)
+	jobsAlertsAmounts := make([]uint64, len(jobsBuffered))
 	for i := range jobsAlertsAmounts {
-		job := jobs[i]
+		job := jobsBuffered[i]
 		jobsAlertsAmounts[i] = job.Length
 	}
 
@@ -150,6 +202,15 @@ func (iw InfoWindow) View() string {
 	fmt.Fprintf(iw.Buffer, "Locked:    %v\n", jobsQueuedLockedIds)
 	fmt.Fprintf(iw.Buffer, "\n")
 
+	jobsCounts := jobs.CountsPerState(simulation.JobsSystem)
+
+	fmt.Fprintf(iw.Buffer, "Jobs:\n")
+	for state, count := range jobsCounts {
+		name := jobs.JobStatesNames[state]
+		fmt.Fprintf(iw.Buffer, "%s:%*v\n", name, 11-len(name), count)
+	}
+	fmt.Fprintf(iw.Buffer, "\n")
+
 	respondersFreeAmount := sparseset.Length(simulation.RespondersSystem.Free)
 	respondersFree := make([]models.ResponderId, 0, respondersFreeAmount)
 	respondersFreeEntries := simulation.RespondersSystem.Free.Dense
@@ -171,14 +232,24 @@ func (iw InfoWindow) View() string {
 	fmt.Fprintf(iw.Buffer, "\n")
 
 	lineWidth := 36
-	metricsAmount := len(simulation.MetricsSystem.Metrics)
+	metricsAmount := metrics.MetricsAmount(simulation.MetricsSystem)
 	metricsToPrint := make([]metrics.Metric, metricsAmount)
 	metricsToPrint = metrics.GetMetrics(simulation.MetricsSystem, metricsToPrint)
 
 	fmt.Fprintf(iw.Buffer, "Metrics:\n")
 	for _, metric := range metricsToPrint {
 		spacesToPrint := lineWidth - len(metric.Name)
-		fmt.Fprintf(iw.Buffer, "%s:%*v\n", metric.Name, spacesToPrint, metric.Value)
+		if metric.Kind != metrics.HistogramMetric {
+			fmt.Fprintf(iw.Buffer, "%s:%*v\n", metric.Name, spacesToPrint, metric.Value)
+
+			continue
+		}
+
+		mean := float64(0)
+		if metric.Value != 0 {
+			mean = metric.Sum / float64(metric.Value)
+		}
+		fmt.Fprintf(iw.Buffer, "%s:%*s\n", metric.Name, spacesToPrint, fmt.Sprintf("n=%d mean=%.2f", metric.Value, mean))
 	}
 
 	spacesToPrint := lineWidth - len("time_in_pool_seconds")
@@ -212,13 +283,11 @@ func (iw InfoWindow) View() string {
 	fmt.Fprintf(iw.Buffer, "%s:%*.2f\n", "duplicate_percentage", spacesToPrint, duplicatePercentage)
 
 	spacesToPrint = lineWidth - len("load_percentage")
-	freeRespsAtomic := &simulation.MetricsSystem.Metrics[metrics.RespondersFreeCounter]
-	busyRespsAtomic := &simulation.MetricsSystem.Metrics[metrics.RespondersBusyCounter]
-	freeResps := atomic.LoadUint64(freeRespsAtomic)
-	busyResps := atomic.LoadUint64(busyRespsAtomic)
+	freeResps := metrics.LoadGauge(simulation.MetricsSystem, metrics.RespondersFreeGauge)
+	busyResps := metrics.LoadGauge(simulation.MetricsSystem, metrics.RespondersBusyGauge)
 	allResps := freeResps + busyResps
 	loadPercentage := float64(0)
-	if skippedJobs != 0 {
+	if allResps != 0 {
 		loadPercentage = float64(busyResps) / float64(allResps)
 	}
 	fmt.Fprintf(iw.Buffer, "%s:%*.2f\n", "load_percentage", spacesToPrint, loadPercentage)
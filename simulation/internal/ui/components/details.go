@@ -0,0 +1,315 @@
+package components
+
+import (
+	"StantStantov/ASS/internal/simulation"
+	"StantStantov/ASS/internal/simulation/agents"
+	"StantStantov/ASS/internal/simulation/jobs"
+	"StantStantov/ASS/internal/simulation/models"
+	"StantStantov/ASS/internal/simulation/responders"
+	"StantStantov/ASS/internal/ui/controls"
+	"StantStantov/ASS/internal/ui/logview"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/StantStantov/rps/swamp/behaivors/buffers"
+	"github.com/StantStantov/rps/swamp/collections/sparsemap"
+	"github.com/StantStantov/rps/swamp/collections/sparseset"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// DetailsPanel shows one agent, job or responder, it is selected with a
+// query like agent=3 and stepped through with the next and previous keys.
+type DetailsPanel struct {
+	Query     textinput.Model
+	Prompting bool
+	Active    bool
+	Entity    logview.EntityType
+	Id        uint64
+	Error     string
+}
+
+func OpenDetailsPrompt(dp DetailsPanel) (DetailsPanel, tea.Cmd) {
+	dp.Query = textinput.New()
+	dp.Query.Prompt = ""
+	if dp.Active {
+		dp.Query.SetValue(fmt.Sprintf("%s=%d", logview.EntitiesNames[dp.Entity], dp.Id))
+	}
+	dp.Prompting = true
+	dp.Error = ""
+
+	return dp, dp.Query.Focus()
+}
+
+// StepDetails selects the neighbouring id, wrapping around at the ends.
+func StepDetails(dp DetailsPanel, step int) DetailsPanel {
+	amount := entitiesAmount(dp.Entity)
+	if amount == 0 {
+		return dp
+	}
+
+	dp.Id = uint64((int(dp.Id%amount) + step + int(amount)) % int(amount))
+
+	return dp
+}
+
+func (dp DetailsPanel) Init() tea.Cmd {
+	return nil
+}
+
+func (dp DetailsPanel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || !dp.Prompting {
+		return dp, nil
+	}
+
+	switch controls.KeyName(keyMsg.String()) {
+	case controls.FormCancelKey:
+		dp.Prompting = false
+
+		return dp, nil
+	case controls.FormSubmitKey:
+		entity, id, err := parseSelection(dp.Query.Value())
+		if err != nil {
+			dp.Error = err.Error()
+
+			return dp, nil
+		}
+
+		dp.Entity = entity
+		dp.Id = id
+		dp.Active = true
+		dp.Prompting = false
+
+		return dp, nil
+	}
+
+	var cmd tea.Cmd
+	dp.Query, cmd = dp.Query.Update(msg)
+
+	return dp, cmd
+}
+
+func (dp DetailsPanel) View() string {
+	builder := &strings.Builder{}
+
+	if dp.Prompting {
+		fmt.Fprintf(builder, "Inspect: %s\n", dp.Query.View())
+		if dp.Error != "" {
+			fmt.Fprintf(builder, "Error: %s\n", dp.Error)
+		}
+		fmt.Fprintf(builder, "\n")
+		fmt.Fprintf(builder, "Select with %s, like agent=3\n", strings.Join(selectionTerms(), ", "))
+		fmt.Fprintf(builder, "\n")
+	}
+	if !dp.Active {
+		return builder.String()
+	}
+
+	switch dp.Entity {
+	case logview.AgentEntity:
+		drawAgentDetails(builder, dp.Id)
+	case logview.JobEntity:
+		drawJobDetails(builder, dp.Id)
+	case logview.ResponderEntity:
+		drawResponderDetails(builder, dp.Id)
+	}
+	fmt.Fprintf(builder, "\n")
+	fmt.Fprintf(builder, "%s/%s: previous/next, %s: close\n", controls.DetailsPreviousKey, controls.DetailsNextKey, controls.FormCancelKey)
+
+	return builder.String()
+}
+
+func drawAgentDetails(builder *strings.Builder, id models.AgentId) {
+	status := "silent"
+	if slices.Contains(simulation.AgentsSystem.Alarmed, id) {
+		status = "alarmed"
+	}
+
+	fmt.Fprintf(builder, "Agent %d:\n", id)
+	fmt.Fprintf(builder, "Status:    %s\n", status)
+	if simulation.AgentsSystem.ArrivalModel != agents.BernoulliArrival {
+		fmt.Fprintf(builder, "Rate:      %.3f\n", simulation.AgentsSystem.Rates[id])
+	}
+	fmt.Fprintf(builder, "Created:   %d\n", simulation.AgentsSystem.Created[id])
+	fmt.Fprintf(builder, "Rewritten: %d\n", simulation.Buffer.Rewritten[id])
+	fmt.Fprintf(builder, "Rejected:  %d\n", simulation.AgentsSystem.Rejected[id])
+	fmt.Fprintf(builder, "Pool:      %s\n", poolStatus(id))
+	fmt.Fprintf(builder, "Job:       %s\n", jobStatus(id))
+	fmt.Fprintf(builder, "\n")
+
+	alertBuffers := make([]buffers.SetBuffer[models.MachineInfo, uint64], 1)
+	arePresent := make([]bool, 1)
+	alertBuffers, arePresent = sparsemap.GetFromSparseMap(simulation.Buffer.Values, alertBuffers, arePresent, id)
+	alerts := []models.MachineInfo{}
+	if arePresent[0] {
+		alerts = buffers.ValuesOfSetBuffer(&alertBuffers[0])
+	}
+
+	fmt.Fprintf(builder, "Buffer:    %d/%d alerts\n", len(alerts), simulation.Buffer.AlertsCapacity)
+	for _, alert := range alerts {
+		fmt.Fprintf(builder, "  severity %d at %.3fs\n", alert.Severity, alert.CreatedAt)
+	}
+}
+
+func drawJobDetails(builder *strings.Builder, id uint64) {
+	fmt.Fprintf(builder, "Job %d:\n", id)
+	fmt.Fprintf(builder, "State:     %s\n", jobStatus(id))
+	fmt.Fprintf(builder, "Pool:      %s\n", poolStatus(id))
+	if responder, ok := responderOfJob(id); ok {
+		fmt.Fprintf(builder, "Responder: %d\n", responder)
+	}
+	fmt.Fprintf(builder, "\n")
+
+	history := jobs.History(simulation.JobsSystem, id)
+	fmt.Fprintf(builder, "History:\n")
+	if len(history) == 0 {
+		fmt.Fprintf(builder, "  none yet\n")
+	}
+	for _, transition := range history {
+		if transition.To == jobs.NewState {
+			fmt.Fprintf(builder, "  %10.3fs created\n", transition.At)
+
+			continue
+		}
+
+		from := jobs.JobStatesNames[transition.From]
+		to := jobs.JobStatesNames[transition.To]
+		fmt.Fprintf(builder, "  %10.3fs %s -> %s\n", transition.At, from, to)
+	}
+}
+
+func drawResponderDetails(builder *strings.Builder, id models.ResponderId) {
+	system := simulation.RespondersSystem
+
+	jobsBusy := make([]models.Job, 1)
+	areBusy := make([]bool, 1)
+	jobsBusy, areBusy = sparsemap.GetFromSparseMap(system.Busy, jobsBusy, areBusy, id)
+	timestamps := make([]float64, 1)
+	areLocked := make([]bool, 1)
+	timestamps, areLocked = sparsemap.GetFromSparseMap(system.TimestampsLocked, timestamps, areLocked, id)
+
+	status := "free"
+	if areBusy[0] {
+		status = "busy"
+	}
+	if id >= uint64(len(system.Responders)) {
+		status = "retired"
+		if areBusy[0] {
+			status = "retiring after its job"
+		}
+	}
+
+	timeBusy := responders.TimesBusy(system, id)[0]
+
+	fmt.Fprintf(builder, "Responder %d:\n", id)
+	fmt.Fprintf(builder, "Status:    %s\n", status)
+	fmt.Fprintf(builder, "Priority:  %d\n", system.RespondersInfo[id].Priority)
+	if areBusy[0] {
+		job := jobsBusy[0]
+		fmt.Fprintf(builder, "Job:       %d with %d alerts\n", job.Id, len(job.Alerts))
+		deadlines := make([]float64, 1)
+		gotDeadlines := make([]bool, 1)
+		deadlines, gotDeadlines = sparsemap.GetFromSparseMap(system.Deadlines, deadlines, gotDeadlines, id)
+		if system.ServiceLaw != responders.ChanceService && gotDeadlines[0] {
+			fmt.Fprintf(builder, "Deadline:  %.3fs\n", deadlines[0])
+		}
+	} else {
+		fmt.Fprintf(builder, "Job:       none\n")
+	}
+	fmt.Fprintf(builder, "Handled:   %d\n", system.Handled[id])
+	fmt.Fprintf(builder, "Busy time: %.3fs\n", timeBusy)
+	if areLocked[0] {
+		fmt.Fprintf(builder, "Assigned:  %.3fs\n", timestamps[0])
+	} else {
+		fmt.Fprintf(builder, "Assigned:  never\n")
+	}
+}
+
+func poolStatus(id uint64) string {
+	arePresent := make([]bool, 1)
+	arePresent = sparsemap.PresentInSparseMap(simulation.Pool.Present, arePresent, id)
+	if !arePresent[0] {
+		return "absent"
+	}
+
+	areLocked := make([]bool, 1)
+	areLocked = sparseset.PresentInSparseSet(simulation.Pool.Locked, areLocked, id)
+	if areLocked[0] {
+		return "locked"
+	}
+
+	timestamps := make([]float64, 1)
+	gotTimestamps := make([]bool, 1)
+	timestamps, gotTimestamps = sparsemap.GetFromSparseMap(simulation.Pool.TimestampsAdded, timestamps, gotTimestamps, id)
+	if !gotTimestamps[0] {
+		return "queued"
+	}
+
+	return fmt.Sprintf("queued since %.3fs", timestamps[0])
+}
+
+func jobStatus(id uint64) string {
+	state, ok := jobs.StateOf(simulation.JobsSystem, id)
+	if !ok {
+		return "none"
+	}
+
+	return jobs.JobStatesNames[state]
+}
+
+func responderOfJob(id uint64) (models.ResponderId, bool) {
+	busyAmount := sparsemap.Length(simulation.RespondersSystem.Busy)
+	respondersBusy := make([]models.ResponderId, busyAmount)
+	jobsBusy := make([]models.Job, busyAmount)
+	respondersBusy, jobsBusy = sparsemap.GetAllFromSparseMap(simulation.RespondersSystem.Busy, respondersBusy, jobsBusy)
+	for i, job := range jobsBusy {
+		if job.Id == id {
+			return respondersBusy[i], true
+		}
+	}
+
+	return 0, false
+}
+
+// Jobs are keyed by the agent that raised them, retired responders stay
+// selectable while they keep their statistics.
+func entitiesAmount(entity logview.EntityType) uint64 {
+	switch entity {
+	case logview.AgentEntity, logview.JobEntity:
+		return uint64(len(simulation.AgentsSystem.AgentsIds))
+	case logview.ResponderEntity:
+		return uint64(len(simulation.RespondersSystem.Handled))
+	}
+
+	return 0
+}
+
+func parseSelection(query string) (logview.EntityType, uint64, error) {
+	name, value, _ := strings.Cut(strings.TrimSpace(query), "=")
+	entity, ok := logview.EntityFromName(name)
+	if !ok {
+		return entity, 0, fmt.Errorf("select one of %s, got %q", strings.Join(selectionTerms(), ", "), query)
+	}
+
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return entity, 0, fmt.Errorf("%s must be an id, got %q", name, value)
+	}
+	if amount := entitiesAmount(entity); id >= amount {
+		return entity, 0, fmt.Errorf("%s must be below %d, got %d", name, amount, id)
+	}
+
+	return entity, id, nil
+}
+
+func selectionTerms() []string {
+	terms := make([]string, len(logview.EntitiesNames))
+	for i, name := range logview.EntitiesNames {
+		terms[i] = name + "="
+	}
+
+	return terms
+}
@@ -0,0 +1,34 @@
+package components
+
+import (
+	"StantStantov/ASS/internal/report"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+func DrawEstimatesTable(output io.Writer, estimates report.Estimates) {
+	general := tabwriter.NewWriter(output, 48, 1, 1, ' ', 0)
+	fmt.Fprintf(general, "%s\n", "Общая статистика:")
+	DrawValue(general, "Количество прогонов", estimates.Replications)
+	DrawPercentage(general, "Доверительная вероятность", estimates.Confidence)
+	DrawValue(general, "Зерна генератора", estimates.Seeds)
+	general.Flush()
+
+	fmt.Fprint(output, "\n")
+
+	statistics := tabwriter.NewWriter(output, 16, 1, 1, ' ', 0)
+	fmt.Fprintf(statistics, "%s\n", "Доверительные интервалы:")
+	fmt.Fprintf(statistics, "%s\t%s\t%s\t%s\t%s\t%s\n", "Показатель", "Среднее", "СКО", "Полуширина", "Нижняя", "Верхняя")
+	for _, estimate := range estimates.Statistics {
+		fmt.Fprintf(statistics, "%s\t%.4f\t%.4f\t%.4f\t%.4f\t%.4f\t\n",
+			estimate.Label,
+			estimate.Mean,
+			estimate.Deviation,
+			estimate.HalfWidth,
+			estimate.Lower,
+			estimate.Upper,
+		)
+	}
+	statistics.Flush()
+}
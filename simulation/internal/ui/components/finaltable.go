@@ -1,134 +1,129 @@
 package components
 
 import (
+	"StantStantov/ASS/internal/report"
 	"StantStantov/ASS/internal/simulation"
-	"StantStantov/ASS/internal/simulation/metrics"
+	"StantStantov/ASS/internal/simulation/buffer"
 	"fmt"
-	"os"
+	"io"
+	"math"
 	"text/tabwriter"
-
-	"github.com/StantStantov/rps/swamp/atomic"
-	"github.com/StantStantov/rps/swamp/collections/sparsemap"
 )
 
-func DrawTable() {
-	allAlertsAtomic := &simulation.MetricsSystem.Metrics[metrics.AlertsBufferedCounter]
-	rewrittenAlertsAtomic := &simulation.MetricsSystem.Metrics[metrics.AlertsRewrittenCounter]
-	allAlerts := atomic.LoadUint64(allAlertsAtomic)
-	rewrittenAlerts := atomic.LoadUint64(rewrittenAlertsAtomic)
-	rewritePercentage := float64(0)
-	if rewrittenAlerts != 0 {
-		rewritePercentage = float64(rewrittenAlerts) / float64(allAlerts)
-	}
-
-	addedJobsAtomic := &simulation.MetricsSystem.Metrics[metrics.JobsPendingCounter]
-	skippedJobsAtomic := &simulation.MetricsSystem.Metrics[metrics.JobsSkippedCounter]
-	addedJobs := atomic.LoadUint64(addedJobsAtomic)
-	skippedJobs := atomic.LoadUint64(skippedJobsAtomic)
-	allJobs := addedJobs + skippedJobs
-	duplicatePercentage := float64(0)
-	if skippedJobs != 0 {
-		duplicatePercentage = float64(skippedJobs) / float64(allJobs)
-	}
-
-	finishedJobsAtomic := &simulation.MetricsSystem.Metrics[metrics.JobsUnlockedCounter]
-	finishedJobs := atomic.LoadUint64(finishedJobsAtomic)
-
-	freeRespsAtomic := &simulation.MetricsSystem.Metrics[metrics.RespondersFreeCounter]
-	busyRespsAtomic := &simulation.MetricsSystem.Metrics[metrics.RespondersBusyCounter]
-	freeResps := atomic.LoadUint64(freeRespsAtomic)
-	busyResps := atomic.LoadUint64(busyRespsAtomic)
-	allResps := freeResps + busyResps
-	loadPercentage := float64(0)
-	if allResps != 0 {
-		loadPercentage = float64(busyResps) / float64(allResps)
-	}
-
-	timeAverage := float64(0)
-	if simulation.Pool.SpentTimeInPool != 0 {
-		timeAverage = simulation.Pool.SpentTimeInPool / float64(simulation.Pool.PoppedAmount)
-	}
+func DrawTable(output io.Writer) {
+	export := report.CollectExport()
+	summary := export.Summary
 
-	writer := tabwriter.NewWriter(os.Stdout, 48, 1, 1, ' ', 0)
+	writer := tabwriter.NewWriter(output, 48, 1, 1, ' ', 0)
 	fmt.Fprintf(writer, "%s\n", "Общая статистика:")
-	DrawValue(writer, "Количество обновлений", simulation.TickCounter)
+	DrawValue(writer, "Зерно генератора", summary.Seed)
+	DrawValue(writer, "Количество обновлений", summary.Ticks)
+	DrawSeconds(writer, "Модельное время", summary.Duration)
+	if simulation.WarmupSystem.IsOver {
+		DrawValue(writer, "Обновлений на разогрев", summary.WarmupTicks)
+		DrawSeconds(writer, "Время разогрева", summary.WarmupTime)
+	} else {
+		DrawValue(writer, "Разогрев", "не завершён")
+	}
 
 	fmt.Fprintf(writer, "%s\n", "Тревоги:")
-	DrawValue(writer, "Количество сохраннёных тревог", allAlerts)
-	DrawValue(writer, "Количество перезаписанных тревог", rewrittenAlerts)
-	DrawPercentage(writer, "Процент перезаписанных", rewritePercentage)
+	DrawValue(writer, "Дисциплина отказа", buffer.RefusalPoliciesNames[simulation.Buffer.RefusalPolicy])
+	DrawValue(writer, "Количество сохраннёных тревог", summary.AlertsSaved)
+	DrawValue(writer, "Количество перезаписанных тревог", summary.AlertsRewritten)
+	DrawValue(writer, "Количество тревог в общей области", summary.AlertsSpilled)
+	DrawPercentage(writer, "Процент перезаписанных", summary.RewritePercentage)
 
 	fmt.Fprintf(writer, "%s\n", "Задачи:")
-	DrawValue(writer, "Количество созданных задач", allJobs)
-	DrawValue(writer, "Количество задач-дупликатов", skippedJobs)
-	DrawPercentage(writer, "Процент дупликатов", duplicatePercentage)
+	DrawValue(writer, "Количество созданных задач", summary.JobsCreated)
+	DrawValue(writer, "Количество задач-дупликатов", summary.JobsDuplicated)
+	DrawPercentage(writer, "Процент дупликатов", summary.DuplicatePercentage)
 
 	fmt.Fprintf(writer, "%s\n", "Обработка задач:")
-	DrawValue(writer, "Количество завершенных задач", finishedJobs)
-	DrawPercentage(writer, "Процент нагрузки", loadPercentage)
-	DrawSeconds(writer, "Среднее время пребывания в системе", timeAverage)
+	DrawValue(writer, "Количество завершенных задач", summary.JobsFinished)
+	DrawPercentage(writer, "Процент нагрузки", summary.LoadPercentage)
+	DrawSeconds(writer, "Среднее время пребывания в системе", summary.TimeInSystem)
 	writer.Flush()
 
-	fmt.Fprint(os.Stdout, "\n")
-
-	ids := simulation.AgentsSystem.AgentsIds
-	timesSpentInPool := make([]float64, len(ids))
-	gotTimesSpentInPool := make([]bool, len(ids))
-	timesSpentInPool, gotTimesSpentInPool = sparsemap.GetFromSparseMap(simulation.Pool.TimeLocked, timesSpentInPool, gotTimesSpentInPool, ids...)
-	timesSpentHandling := make([]float64, len(ids))
-	gotTimesSpentHandling := make([]bool, len(ids))
-	timesSpentHandling, gotTimesSpentHandling = sparsemap.GetFromSparseMap(simulation.Pool.TimeUnlocked, timesSpentHandling, gotTimesSpentHandling, ids...)
+	fmt.Fprint(output, "\n")
 
-	sources := tabwriter.NewWriter(os.Stdout, 16, 1, 1, ' ', 0)
+	sources := tabwriter.NewWriter(output, 16, 1, 1, ' ', 0)
 	fmt.Fprintf(sources, "%s\n", "Статистика по источникам:")
 	fmt.Fprintf(sources, "%s\t%s\t%s\t%s\t%s\n", "ID", "Создано", "Перезаписанно", "T БП", "T Обсл")
-	for _, id := range ids {
-		created := simulation.AgentsSystem.Created[id]
-		rewritten := simulation.Buffer.Rewritten[id]
-		timeSpentInPool := timesSpentInPool[id]
-		timeSpentHandling := timesSpentHandling[id]
-
+	for _, row := range export.Sources {
 		fmt.Fprintf(sources, "%d\t%d\t%d\t%.2f\t%.2f\n",
-			id,
-			created,
-			rewritten,
-			timeSpentInPool,
-			timeSpentHandling,
+			row.Id,
+			row.Created,
+			row.Rewritten,
+			row.TimeInPool,
+			row.TimeHandling,
 		)
 	}
 	sources.Flush()
 
-	fmt.Fprint(os.Stdout, "\n")
-
-	idsHandlers := simulation.RespondersSystem.Responders
-	timesHandlersSpentHandling := make([]float64, len(idsHandlers))
-	gotHandlersTimesSpentHandling := make([]bool, len(idsHandlers))
-	timesHandlersSpentHandling, gotHandlersTimesSpentHandling = sparsemap.GetFromSparseMap(
-		simulation.RespondersSystem.TimeUnlocked,
-		timesHandlersSpentHandling,
-		gotHandlersTimesSpentHandling,
-		idsHandlers...,
-	)
+	fmt.Fprint(output, "\n")
 
-	handlers := tabwriter.NewWriter(os.Stdout, 16, 1, 1, ' ', 0)
+	handlers := tabwriter.NewWriter(output, 16, 1, 1, ' ', 0)
 	fmt.Fprintf(handlers, "%s\n", "Статистика по приборам:")
-	fmt.Fprintf(handlers, "%s\t%s\t%s\n", "ID", "P Обсл", "T Обсл")
-	for _, id := range idsHandlers {
-		// all := simulation.RespondersSystem.All[id]
-		handled := simulation.RespondersSystem.Handled[id]
-		percentage := float64(0)
-		if handled != 0 && finishedJobs != 0 {
-			percentage = float64(handled) / float64(finishedJobs)
-		}
-		timeSpentHandling := timesHandlersSpentHandling[id]
-
-		fmt.Fprintf(handlers, "%d\t%.2f\t%.2f\t\n",
-			id,
-			percentage,
-			timeSpentHandling,
+	fmt.Fprintf(handlers, "%s\t%s\t%s\t%s\t%s\n", "ID", "Приоритет", "P Обсл", "T Обсл", "Загрузка")
+	for _, row := range export.Responders {
+		fmt.Fprintf(handlers, "%d\t%d\t%.2f\t%.2f\t%.2f\t\n",
+			row.Id,
+			row.Priority,
+			row.HandledShare,
+			row.TimeHandling,
+			row.Utilisation,
 		)
 	}
 	handlers.Flush()
+
+	fmt.Fprint(output, "\n")
+
+	byPriority := tabwriter.NewWriter(output, 16, 1, 1, ' ', 0)
+	fmt.Fprintf(byPriority, "%s\n", "Статистика по приоритетам:")
+	fmt.Fprintf(byPriority, "%s\t%s\t%s\t%s\t%s\n", "Приоритет", "Приборов", "Обслужено", "P Обсл", "Загрузка")
+	for _, row := range export.Priorities {
+		fmt.Fprintf(byPriority, "%d\t%d\t%d\t%.2f\t%.2f\t\n",
+			row.Priority,
+			row.Responders,
+			row.Handled,
+			row.HandledShare,
+			row.Utilisation,
+		)
+	}
+	byPriority.Flush()
+
+	fmt.Fprint(output, "\n")
+
+	DrawAnalyticalTable(output, report.CollectAnalytical(summary))
+}
+
+func DrawAnalyticalTable(output io.Writer, analytical report.Analytical) {
+	rates := tabwriter.NewWriter(output, 48, 1, 1, ' ', 0)
+	fmt.Fprintf(rates, "%s\n", "Сравнение с аналитическими моделями:")
+	DrawValue(rates, "Интенсивность принятых задач (M/M/c)", fmt.Sprintf("%.4f в секунду", analytical.AdmittedRate))
+	DrawValue(rates, "Интенсивность всех задач (M/M/c/K)", fmt.Sprintf("%.4f в секунду", analytical.OfferedRate))
+	DrawValue(rates, "Интенсивность обслуживания", fmt.Sprintf("%.4f в секунду", analytical.ServiceRate))
+	DrawValue(rates, "Количество приборов (c)", analytical.Servers)
+	DrawValue(rates, "Вместимость системы (K)", analytical.Capacity)
+	if !analytical.MMc.IsStable {
+		DrawValue(rates, "M/M/c", "неустойчива при такой нагрузке")
+	}
+	rates.Flush()
+
+	comparisons := tabwriter.NewWriter(output, 16, 1, 1, ' ', 0)
+	fmt.Fprintf(comparisons, "%s\t%s\t%s\t%s\t%s\t%s\n", "Показатель", "Симуляция", "M/M/c", "Откл.", "M/M/c/K", "Откл.")
+	for _, comparison := range analytical.Comparisons {
+		fmt.Fprintf(comparisons, "%s\t%.4f\t%s\t%s\t%s\t%s\t\n",
+			comparison.Label,
+			comparison.Simulated,
+			formatPrediction(comparison.MMc),
+			formatDeviation(report.RelativeDeviation(comparison.Simulated, comparison.MMc)),
+			formatPrediction(comparison.MMcK),
+			formatDeviation(report.RelativeDeviation(comparison.Simulated, comparison.MMcK)),
+		)
+	}
+	comparisons.Flush()
+	fmt.Fprintf(output, "%s\n", "Модели считают источник бесконечным, а число агентов конечно: агент с задачей в системе не создаёт новых, поэтому ожидание в симуляции короче.")
 }
 
 func DrawValue(writer *tabwriter.Writer, key string, value any) {
@@ -139,6 +134,22 @@ func DrawPercentage(writer *tabwriter.Writer, key string, value float64) {
 	fmt.Fprintf(writer, "%s:\t%.2f\n", key, value)
 }
 
+func formatPrediction(value float64) string {
+	if math.IsNaN(value) {
+		return "—"
+	}
+
+	return fmt.Sprintf("%.4f", value)
+}
+
+func formatDeviation(value float64) string {
+	if math.IsNaN(value) {
+		return "—"
+	}
+
+	return fmt.Sprintf("%+.1f%%", value*100)
+}
+
 func DrawSeconds(writer *tabwriter.Writer, key string, value float64) {
 	fmt.Fprintf(writer, "%s:\t%.2f секунд\n", key, value)
 }
@@ -0,0 +1,220 @@
+package components
+
+import (
+	"StantStantov/ASS/internal/simulation/loghistory"
+	"StantStantov/ASS/internal/ui/controls"
+	"StantStantov/ASS/internal/ui/logview"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const logsHeaderHeight = 1
+
+var highlightStyle = lipgloss.NewStyle().Reverse(true)
+
+type LogsPrompt uint8
+
+const (
+	NoLogsPrompt LogsPrompt = iota
+	FilterLogsPrompt
+	TickLogsPrompt
+)
+
+var LogsPromptsNames = []string{
+	"",
+	"Filter",
+	"Tick",
+}
+
+// LogsWindow shows the lines of the log history that pass the filter of
+// Index. It follows the newest lines until it is frozen, a frozen window
+// shows a page starting at TopLine.
+type LogsWindow struct {
+	Buffer  *strings.Builder
+	History *loghistory.History
+	Index   *logview.Index
+
+	Query       textinput.Model
+	Prompt      LogsPrompt
+	FilterQuery string
+	Error       string
+	Frozen      bool
+	TopLine     uint64
+
+	viewport.Model
+}
+
+func OpenLogsPrompt(lw LogsWindow, prompt LogsPrompt) (LogsWindow, tea.Cmd) {
+	lw.Query = textinput.New()
+	lw.Query.Prompt = ""
+	if prompt == FilterLogsPrompt {
+		lw.Query.SetValue(lw.FilterQuery)
+	}
+	lw.Prompt = prompt
+	lw.Error = ""
+
+	return lw, lw.Query.Focus()
+}
+
+func ToggleLogsFreeze(lw LogsWindow) LogsWindow {
+	logview.Refresh(lw.Index, lw.History)
+	if !lw.Frozen {
+		lw.TopLine = logview.LineAt(lw.Index, tailPosition(lw))
+	}
+	lw.Frozen = !lw.Frozen
+
+	return lw
+}
+
+// ScrollLogs moves a frozen window by pages, scrolling a following window
+// freezes it at the newest page first.
+func ScrollLogs(lw LogsWindow, pages int) LogsWindow {
+	if !lw.Frozen {
+		lw = ToggleLogsFreeze(lw)
+	}
+
+	position := logview.Position(lw.Index, lw.TopLine) + pages*lw.Model.Height
+	position = min(max(position, 0), tailPosition(lw))
+	lw.TopLine = logview.LineAt(lw.Index, position)
+
+	return lw
+}
+
+func (lw LogsWindow) Init() tea.Cmd {
+	return nil
+}
+
+func (lw LogsWindow) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || lw.Prompt == NoLogsPrompt {
+		return lw, nil
+	}
+
+	switch controls.KeyName(keyMsg.String()) {
+	case controls.FormCancelKey:
+		lw.Prompt = NoLogsPrompt
+
+		return lw, nil
+	case controls.FormSubmitKey:
+		var err error
+		switch lw.Prompt {
+		case FilterLogsPrompt:
+			lw, err = applyLogsFilter(lw, lw.Query.Value())
+		case TickLogsPrompt:
+			lw, err = jumpToTick(lw, lw.Query.Value())
+		}
+		if err != nil {
+			lw.Error = strings.ReplaceAll(err.Error(), "\n", "; ")
+
+			return lw, nil
+		}
+
+		lw.Prompt = NoLogsPrompt
+
+		return lw, nil
+	}
+
+	var cmd tea.Cmd
+	lw.Query, cmd = lw.Query.Update(msg)
+
+	return lw, cmd
+}
+
+func (lw LogsWindow) View() string {
+	defer lw.Buffer.Reset()
+
+	logview.Refresh(lw.Index, lw.History)
+	position := tailPosition(lw)
+	if lw.Frozen {
+		position = logview.Position(lw.Index, lw.TopLine)
+	}
+	page := lw.Index.Lines[position:min(position+lw.Model.Height, len(lw.Index.Lines))]
+
+	entries := []loghistory.Entry{}
+	start := uint64(0)
+	if len(page) != 0 {
+		start, entries = loghistory.Lines(lw.History, page[0], page[len(page)-1]+1)
+	}
+	shown := make([]loghistory.Entry, 0, len(page))
+	for _, line := range page {
+		if line < start || line-start >= uint64(len(entries)) {
+			continue
+		}
+
+		entry := entries[line-start]
+		shown = append(shown, entry)
+		lw.Buffer.WriteString(logview.Highlight(lw.Index.Filter, entry.Line, highlightStyle))
+		lw.Buffer.WriteString("\n")
+	}
+
+	logsToRender := strings.TrimSuffix(lw.Buffer.String(), "\n")
+	logsToRenderWrapped := lipgloss.NewStyle().Width(lw.Model.Width).Render(logsToRender)
+
+	lw.Model.SetContent(logsToRenderWrapped)
+	if !lw.Frozen {
+		lw.Model.GotoBottom()
+	}
+
+	header := lipgloss.NewStyle().MaxWidth(lw.Model.Width).Render(logsHeader(lw, position, shown))
+
+	return lipgloss.JoinVertical(lipgloss.Left, header, lw.Model.View())
+}
+
+func logsHeader(lw LogsWindow, position int, shown []loghistory.Entry) string {
+	if lw.Prompt != NoLogsPrompt {
+		header := fmt.Sprintf("%s: %s", LogsPromptsNames[lw.Prompt], lw.Query.View())
+		if lw.Error != "" {
+			header += fmt.Sprintf(" Error: %s", lw.Error)
+		}
+
+		return header
+	}
+
+	header := fmt.Sprintf("Logs: %d-%d of %d lines", min(position+1, len(lw.Index.Lines)), position+len(shown), len(lw.Index.Lines))
+	if len(shown) != 0 {
+		header += fmt.Sprintf(", ticks %d-%d", shown[0].Tick, shown[len(shown)-1].Tick)
+	}
+	if !logview.IsEmpty(lw.Index.Filter) {
+		header += fmt.Sprintf(", filter: %s", lw.FilterQuery)
+	}
+	if lw.Frozen {
+		header += ", frozen"
+	}
+
+	return header
+}
+
+func applyLogsFilter(lw LogsWindow, query string) (LogsWindow, error) {
+	filter, err := logview.ParseQuery(query)
+	if err != nil {
+		return lw, err
+	}
+
+	lw.FilterQuery = query
+	lw.Index = logview.NewIndex(filter)
+	logview.Refresh(lw.Index, lw.History)
+
+	return lw, nil
+}
+
+func jumpToTick(lw LogsWindow, value string) (LogsWindow, error) {
+	tick, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return lw, fmt.Errorf("tick must be a number, got %q", value)
+	}
+
+	lw.Frozen = true
+	lw.TopLine = loghistory.LineOfTick(lw.History, tick)
+
+	return lw, nil
+}
+
+func tailPosition(lw LogsWindow) int {
+	return max(len(lw.Index.Lines)-lw.Model.Height, 0)
+}
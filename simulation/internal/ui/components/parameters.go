@@ -0,0 +1,153 @@
+package components
+
+import (
+	"StantStantov/ASS/internal/simulation"
+	"StantStantov/ASS/internal/simulation/commands"
+	"StantStantov/ASS/internal/ui/controls"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	minChanceToCrashField = iota
+	minChanceToHandleField
+	alertsCapacityField
+	respondersAmountField
+)
+
+var ParametersFieldsNames = []string{
+	"min_chance_to_crash",
+	"min_chance_to_handle",
+	"alerts_capacity",
+	"responders_amount",
+}
+
+// ParametersForm edits the simulation parameters, the changes are applied
+// between ticks through the commands system.
+type ParametersForm struct {
+	CommandsSystem *commands.CommandsSystem
+
+	Inputs  []textinput.Model
+	Focused int
+	Active  bool
+	Error   string
+}
+
+func OpenParametersForm(form ParametersForm) (ParametersForm, tea.Cmd) {
+	parameters := simulation.CurrentParameters()
+	values := []string{
+		minChanceToCrashField:  strconv.FormatFloat(float64(parameters.MinChanceToCrash), 'g', -1, 32),
+		minChanceToHandleField: strconv.FormatFloat(float64(parameters.MinChanceToHandle), 'g', -1, 32),
+		alertsCapacityField:    strconv.FormatUint(parameters.AlertsCapacity, 10),
+		respondersAmountField:  strconv.FormatUint(parameters.RespondersAmount, 10),
+	}
+
+	form.Inputs = make([]textinput.Model, len(values))
+	for i, value := range values {
+		input := textinput.New()
+		input.Prompt = ""
+		input.CharLimit = 16
+		input.SetValue(value)
+		form.Inputs[i] = input
+	}
+	form.Focused = 0
+	form.Active = true
+	form.Error = ""
+
+	return form, form.Inputs[form.Focused].Focus()
+}
+
+func (pf ParametersForm) Init() tea.Cmd {
+	return nil
+}
+
+func (pf ParametersForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return pf, nil
+	}
+
+	switch controls.KeyName(keyMsg.String()) {
+	case controls.FormCancelKey:
+		pf.Active = false
+
+		return pf, nil
+	case controls.FormNextKey:
+		return focusField(pf, (pf.Focused+1)%len(pf.Inputs))
+	case controls.FormPreviousKey:
+		return focusField(pf, (pf.Focused+len(pf.Inputs)-1)%len(pf.Inputs))
+	case controls.FormSubmitKey:
+		parameters, err := parseParameters(pf)
+		if err == nil {
+			err = simulation.RequestParameters(parameters)
+		}
+		if err != nil {
+			pf.Error = err.Error()
+
+			return pf, nil
+		}
+
+		commands.EnqueqeCommands(pf.CommandsSystem, commands.ParametersCommand)
+		pf.Active = false
+
+		return pf, nil
+	}
+
+	var cmd tea.Cmd
+	pf.Inputs[pf.Focused], cmd = pf.Inputs[pf.Focused].Update(msg)
+
+	return pf, cmd
+}
+
+func (pf ParametersForm) View() string {
+	builder := &strings.Builder{}
+
+	fmt.Fprintf(builder, "Parameters:\n")
+	for i, input := range pf.Inputs {
+		cursor := " "
+		if i == pf.Focused {
+			cursor = ">"
+		}
+		name := ParametersFieldsNames[i]
+		fmt.Fprintf(builder, "%s %s:%*s%s\n", cursor, name, 22-len(name), "", input.View())
+	}
+	fmt.Fprintf(builder, "\n")
+
+	if pf.Error != "" {
+		fmt.Fprintf(builder, "Error: %s\n\n", pf.Error)
+	}
+	fmt.Fprintf(builder, "%s/%s: move, %s: apply, %s: cancel\n", controls.FormNextKey, controls.FormPreviousKey, controls.FormSubmitKey, controls.FormCancelKey)
+
+	return builder.String()
+}
+
+func focusField(form ParametersForm, field int) (ParametersForm, tea.Cmd) {
+	form.Inputs[form.Focused].Blur()
+	form.Focused = field
+
+	return form, form.Inputs[form.Focused].Focus()
+}
+
+func parseParameters(form ParametersForm) (simulation.Parameters, error) {
+	parameters := simulation.Parameters{}
+	errs := make([]error, len(form.Inputs))
+
+	minChanceToCrash, err := strconv.ParseFloat(form.Inputs[minChanceToCrashField].Value(), 32)
+	parameters.MinChanceToCrash, errs[minChanceToCrashField] = float32(minChanceToCrash), err
+	minChanceToHandle, err := strconv.ParseFloat(form.Inputs[minChanceToHandleField].Value(), 32)
+	parameters.MinChanceToHandle, errs[minChanceToHandleField] = float32(minChanceToHandle), err
+	parameters.AlertsCapacity, errs[alertsCapacityField] = strconv.ParseUint(form.Inputs[alertsCapacityField].Value(), 10, 64)
+	parameters.RespondersAmount, errs[respondersAmountField] = strconv.ParseUint(form.Inputs[respondersAmountField].Value(), 10, 64)
+
+	for i, err := range errs {
+		if err != nil {
+			return parameters, fmt.Errorf("%s: %w", ParametersFieldsNames[i], err)
+		}
+	}
+
+	return parameters, nil
+}
@@ -9,9 +9,34 @@ type KeyName string
 var (
 	QuitKey KeyName = "q"
 	PauseKey KeyName = " "
+	ChartWindowKey KeyName = "w"
+	SnapshotKey KeyName = "s"
+	SpeedUpKey KeyName = "+"
+	SlowDownKey KeyName = "-"
+	StepKey KeyName = "n"
+	ParametersKey KeyName = "e"
+	LogsSearchKey KeyName = "/"
+	LogsFreezeKey KeyName = "f"
+	LogsTickKey KeyName = "t"
+	LogsPageUpKey KeyName = "pgup"
+	LogsPageDownKey KeyName = "pgdown"
+	DetailsKey KeyName = "i"
+	DetailsPreviousKey KeyName = "["
+	DetailsNextKey KeyName = "]"
+)
+
+var (
+	FormNextKey KeyName = "tab"
+	FormPreviousKey KeyName = "shift+tab"
+	FormSubmitKey KeyName = "enter"
+	FormCancelKey KeyName = "esc"
 )
 
 var Keybindings = map[KeyName]commands.CommandType{
 	QuitKey: commands.QuitCommand,
 	PauseKey: commands.PauseCommand,
+	SnapshotKey: commands.SnapshotCommand,
+	SpeedUpKey: commands.SpeedUpCommand,
+	SlowDownKey: commands.SlowDownCommand,
+	StepKey: commands.StepCommand,
 }
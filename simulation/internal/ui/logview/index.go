@@ -0,0 +1,55 @@
+package logview
+
+import (
+	"StantStantov/ASS/internal/simulation/loghistory"
+	"sort"
+)
+
+// Index keeps the indices of the history lines passing Filter, it scans
+// only the lines written since the last refresh.
+type Index struct {
+	Filter  Filter
+	Lines   []uint64
+	Scanned uint64
+}
+
+func NewIndex(filter Filter) *Index {
+	index := &Index{}
+
+	index.Filter = filter
+	index.Lines = []uint64{}
+	index.Scanned = 0
+
+	return index
+}
+
+func Refresh(index *Index, history *loghistory.History) {
+	first, end := loghistory.Bounds(history)
+	start, entries := loghistory.Lines(history, max(index.Scanned, first), end)
+
+	kept := Position(index, first)
+	index.Lines = append(index.Lines[:0], index.Lines[kept:]...)
+	for i, entry := range entries {
+		if Matches(index.Filter, entry.Line) {
+			index.Lines = append(index.Lines, start+uint64(i))
+		}
+	}
+	index.Scanned = start + uint64(len(entries))
+}
+
+// Position returns how many indexed lines come before line.
+func Position(index *Index, line uint64) int {
+	return sort.Search(len(index.Lines), func(i int) bool {
+		return index.Lines[i] >= line
+	})
+}
+
+// LineAt returns the line at position, or the next line to be scanned
+// past the last position.
+func LineAt(index *Index, position int) uint64 {
+	if position < len(index.Lines) {
+		return index.Lines[position]
+	}
+
+	return index.Scanned
+}
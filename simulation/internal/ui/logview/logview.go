@@ -0,0 +1,283 @@
+package logview
+
+import (
+	"StantStantov/ASS/internal/config"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/StantStantov/rps/swamp/logging"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type Field struct {
+	Key   string
+	Value string
+}
+
+type EntityType uint8
+
+const (
+	JobEntity EntityType = iota
+	AgentEntity
+	ResponderEntity
+
+	entitiesAmount
+)
+
+var EntitiesNames = []string{
+	"job",
+	"agent",
+	"responder",
+}
+
+// EntitiesKeys are the first segments of the keys holding ids of an entity,
+// like jobs.ids or responders.freed.ids.
+var EntitiesKeys = []string{
+	"jobs",
+	"agents",
+	"responders",
+}
+
+func EntityFromName(name string) (EntityType, bool) {
+	index := slices.Index(EntitiesNames, name)
+	if index < 0 {
+		return JobEntity, false
+	}
+
+	return EntityType(index), true
+}
+
+// Filter keeps lines from any of From, at Level or above, mentioning any
+// of Ids and containing Text.
+type Filter struct {
+	From     []string
+	Level    logging.Level
+	HasLevel bool
+	Ids      [entitiesAmount][]uint64
+	Text     string
+}
+
+// ParseQuery reads a query of from=, level=, job=, agent= and responder=
+// terms, every other word is searched for as free text.
+func ParseQuery(query string) (Filter, error) {
+	filter := Filter{}
+	words := []string{}
+	errs := []error{}
+
+	for _, term := range strings.Fields(query) {
+		key, value, ok := strings.Cut(term, "=")
+		if !ok {
+			words = append(words, term)
+
+			continue
+		}
+
+		switch key {
+		case "from":
+			filter.From = append(filter.From, value)
+		case "level":
+			level, ok := ParseLevel(value)
+			if !ok {
+				errs = append(errs, fmt.Errorf("level must be one of debug, info, warn or error, got %q", value))
+			}
+			filter.Level = level
+			filter.HasLevel = true
+		default:
+			entity, ok := EntityFromName(key)
+			if !ok {
+				words = append(words, term)
+
+				continue
+			}
+
+			id, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s must be an id, got %q", key, value))
+			}
+			filter.Ids[entity] = append(filter.Ids[entity], id)
+		}
+	}
+	filter.Text = strings.Join(words, " ")
+
+	return filter, errors.Join(errs...)
+}
+
+// ParseLevel accepts both the numeric and the named form of a level.
+func ParseLevel(value string) (logging.Level, bool) {
+	if number, err := strconv.ParseUint(value, 10, 8); err == nil {
+		return logging.Level(number), true
+	}
+
+	lowered := strings.ToLower(value)
+	if lowered == "" {
+		return logging.LevelDebug, false
+	}
+	for name, level := range config.LogLevelsNames {
+		if strings.HasPrefix(lowered, name) || strings.HasPrefix(name, lowered) {
+			return level, true
+		}
+	}
+
+	return logging.LevelDebug, false
+}
+
+func IsEmpty(filter Filter) bool {
+	return filter.Text == "" && !hasFields(filter)
+}
+
+func Matches(filter Filter, line string) bool {
+	if filter.Text != "" && len(textIndices(line, filter.Text)) == 0 {
+		return false
+	}
+	if !hasFields(filter) {
+		return true
+	}
+
+	fields := ParseLine(line)
+	if len(filter.From) != 0 {
+		from, _ := valueOf(fields, "from")
+		if !slices.Contains(filter.From, from) {
+			return false
+		}
+	}
+	if filter.HasLevel {
+		value, _ := valueOf(fields, "level")
+		level, ok := ParseLevel(value)
+		if !ok || level < filter.Level {
+			return false
+		}
+	}
+	for entity, ids := range filter.Ids {
+		if len(ids) != 0 && !mentionsAny(fields, EntityType(entity), ids) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Highlight renders every occurrence of the filter text with style.
+func Highlight(filter Filter, line string, style lipgloss.Style) string {
+	if filter.Text == "" {
+		return line
+	}
+
+	builder := &strings.Builder{}
+	last := 0
+	for _, start := range textIndices(line, filter.Text) {
+		end := start + len(filter.Text)
+		builder.WriteString(line[last:start])
+		builder.WriteString(style.Render(line[start:end]))
+		last = end
+	}
+	builder.WriteString(line[last:])
+
+	return builder.String()
+}
+
+// ParseLine splits a logfmt line into its fields, quoted values are
+// unquoted, lists keep their brackets and bare words are skipped.
+func ParseLine(line string) []Field {
+	fields := []Field{}
+
+	rest := line
+	for {
+		key, value, ok := strings.Cut(rest, "=")
+		if !ok {
+			return fields
+		}
+		if space := strings.LastIndexByte(key, ' '); space >= 0 {
+			key = key[space+1:]
+		}
+
+		field := Field{Key: key}
+		switch {
+		case strings.HasPrefix(value, `"`):
+			quoted, err := strconv.QuotedPrefix(value)
+			if err != nil {
+				return fields
+			}
+			field.Value, _ = strconv.Unquote(quoted)
+			rest = value[len(quoted):]
+		case strings.HasPrefix(value, "["):
+			end := strings.IndexByte(value, ']')
+			if end < 0 {
+				end = len(value) - 1
+			}
+			field.Value = value[:end+1]
+			rest = value[end+1:]
+		default:
+			end := strings.IndexByte(value, ' ')
+			if end < 0 {
+				end = len(value)
+			}
+			field.Value = value[:end]
+			rest = value[end:]
+		}
+
+		fields = append(fields, field)
+	}
+}
+
+func hasFields(filter Filter) bool {
+	hasIds := false
+	for _, ids := range filter.Ids {
+		hasIds = hasIds || len(ids) != 0
+	}
+
+	return len(filter.From) != 0 || filter.HasLevel || hasIds
+}
+
+func valueOf(fields []Field, key string) (string, bool) {
+	for _, field := range fields {
+		if field.Key == key {
+			return field.Value, true
+		}
+	}
+
+	return "", false
+}
+
+func mentionsAny(fields []Field, entity EntityType, ids []uint64) bool {
+	for _, field := range fields {
+		segments := strings.Split(field.Key, ".")
+		first, last := segments[0], segments[len(segments)-1]
+		if first != EntitiesKeys[entity] || (last != "ids" && last != "id") {
+			continue
+		}
+
+		values := strings.Fields(strings.Trim(field.Value, "[]"))
+		for _, value := range values {
+			id, err := strconv.ParseUint(value, 10, 64)
+			if err == nil && slices.Contains(ids, id) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Text is searched case insensitively whenever lowering keeps byte offsets.
+func textIndices(line, text string) []int {
+	haystack, needle := strings.ToLower(line), strings.ToLower(text)
+	if len(haystack) != len(line) || len(needle) != len(text) {
+		haystack, needle = line, text
+	}
+
+	indices := []int{}
+	for offset := 0; offset <= len(haystack); {
+		index := strings.Index(haystack[offset:], needle)
+		if index < 0 {
+			break
+		}
+
+		indices = append(indices, offset+index)
+		offset += index + max(len(needle), 1)
+	}
+
+	return indices
+}
@@ -1,11 +1,14 @@
 package ui
 
 import (
+	"StantStantov/ASS/internal/report"
 	"StantStantov/ASS/internal/simulation"
 	"StantStantov/ASS/internal/simulation/commands"
-	"StantStantov/ASS/internal/simulation/framebuffer"
+	"StantStantov/ASS/internal/simulation/loghistory"
 	"StantStantov/ASS/internal/ui/components"
 	"StantStantov/ASS/internal/ui/input"
+	"StantStantov/ASS/internal/ui/logview"
+	"io"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -13,18 +16,32 @@ import (
 
 var Tea *tea.Program
 
-func Init(commandsSystem *commands.CommandsSystem, logsBuffer *framebuffer.Buffer) {
+func Init(commandsSystem *commands.CommandsSystem, logHistory *loghistory.History) {
 	input := input.NewInputSystem(commandsSystem)
 
-	commands.RegisterCommand(commandsSystem, commands.QuitCommand, StopEventLoop)
+	commands.RegisterCommand(commandsSystem, commands.QuitCommand, func() {
+		if simulation.Config.SnapshotPath != "" {
+			simulation.SaveSnapshot(simulation.Config.SnapshotPath)
+		}
+		StopEventLoop()
+	})
 	commands.RegisterCommand(commandsSystem, commands.PauseCommand, func() {
 		simulation.IsPaused = !simulation.IsPaused
 	})
+	commands.RegisterCommand(commandsSystem, commands.SpeedUpCommand, simulation.SpeedUp)
+	commands.RegisterCommand(commandsSystem, commands.SlowDownCommand, simulation.SlowDown)
+	commands.RegisterCommand(commandsSystem, commands.StepCommand, simulation.Step)
+	commands.RegisterCommand(commandsSystem, commands.ParametersCommand, simulation.ApplyParameters)
+	commands.RegisterCommand(commandsSystem, commands.SnapshotCommand, func() {
+		simulation.SaveSnapshot(simulation.Config.SnapshotPath)
+	})
 
 	mainMenu := components.MainMenu{
-		Input: input,
-		Info:  components.InfoWindow{Buffer: &strings.Builder{}},
-		Logs:  components.LogsWindow{Buffer: &strings.Builder{}, LogBuffer: logsBuffer},
+		Input:      input,
+		Info:       components.InfoWindow{Buffer: &strings.Builder{}},
+		Charts:     components.ChartsWindow{Buffer: &strings.Builder{}},
+		Logs:       components.LogsWindow{Buffer: &strings.Builder{}, History: logHistory, Index: logview.NewIndex(logview.Filter{})},
+		Parameters: components.ParametersForm{CommandsSystem: commandsSystem},
 	}
 	Tea = tea.NewProgram(
 		mainMenu,
@@ -41,6 +58,10 @@ func StopEventLoop() {
 	Tea.Wait()
 }
 
-func DrawFinalTable() {
-	components.DrawTable()
+func DrawFinalTable(writer io.Writer) {
+	components.DrawTable(writer)
+}
+
+func DrawEstimatesTable(writer io.Writer, estimates report.Estimates) {
+	components.DrawEstimatesTable(writer, estimates)
 }